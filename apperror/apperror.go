@@ -0,0 +1,128 @@
+// Package apperror holds the domain errors returned by the repositories and
+// services. The error middleware turns them into HTTP responses, so handlers
+// only pass them on and never inspect error messages.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Kind groups errors that map to the same HTTP status
+type Kind string
+
+const (
+	KindNotFound          Kind = "not_found"
+	KindConflict          Kind = "conflict"
+	KindValidation        Kind = "validation"
+	KindForbidden         Kind = "forbidden"
+	KindUnauthorized      Kind = "unauthorized"
+	KindInsufficientStock Kind = "insufficient_stock"
+	KindTooManyRequests   Kind = "too_many_requests"
+)
+
+// Error is a domain error. Code is the machine-readable identifier sent to
+// clients; Message is the human-readable text and may change freely.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+
+	// RetryAfter is sent as the Retry-After header of a too-many-requests error
+	RetryAfter time.Duration
+
+	// Cause is the underlying error, e.g. the binding error of an invalid request
+	Cause error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Status is the HTTP status an error of this kind is answered with
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict, KindInsufficientStock:
+		return http.StatusConflict
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func TooManyRequests(code, message string, retryAfter time.Duration, details interface{}) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message, Details: details, RetryAfter: retryAfter}
+}
+
+// StockShortage describes the product an outbound activity ran short of
+type StockShortage struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Available   int    `json:"available"`
+	Requested   int    `json:"requested"`
+}
+
+func InsufficientStock(shortage StockShortage) *Error {
+	return &Error{
+		Kind:    KindInsufficientStock,
+		Code:    "insufficient_stock",
+		Message: fmt.Sprintf("insufficient stock for product %s", shortage.ProductName),
+		Details: shortage,
+	}
+}
+
+// InvalidRequest wraps a request binding error. The error middleware replaces
+// its message with per-field messages in the client's language.
+func InvalidRequest(err error) *Error {
+	return &Error{Kind: KindValidation, Code: "invalid_request", Message: err.Error(), Cause: err}
+}
+
+// As returns the domain error inside err, if there is one
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// HasCode reports whether err is a domain error with the given code
+func HasCode(err error, code string) bool {
+	e, ok := As(err)
+	return ok && e.Code == code
+}
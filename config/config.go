@@ -0,0 +1,399 @@
+// Package config loads the application settings once at startup. Values come
+// from, in increasing priority: the defaults below, a YAML file, a .env file
+// and the process environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the YAML file read when CONFIG_FILE is not set. It is optional.
+const DefaultFile = "config.yaml"
+
+// Config holds every setting of the application. Each field is read from the
+// environment variable in its env tag and from the YAML key in its yaml tag.
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	JWT       JWT       `yaml:"jwt"`
+	SMTP      SMTP      `yaml:"smtp"`
+	Login     Login     `yaml:"login"`
+	Workshop  Workshop  `yaml:"workshop"`
+	Scheduler Scheduler `yaml:"scheduler"`
+	Log       Log       `yaml:"log"`
+	Metrics   Metrics   `yaml:"metrics"`
+}
+
+type Server struct {
+	Port              string        `yaml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// ShutdownTimeout bounds how long in-flight requests and background jobs may take to finish on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// DrainDelay is how long the server keeps serving after /readyz turns 503 on
+	// SIGTERM, so load balancers notice before connections are refused
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-For is believed; without any, the client IP is the peer address
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+type Database struct {
+	Driver   string `yaml:"driver" env:"DB_DRIVER"`
+	DSN      Secret `yaml:"dsn" env:"DB_DSN"` // overrides the other connection settings
+	User     string `yaml:"user" env:"DB_USER"`
+	Password Secret `yaml:"password" env:"DB_PASS"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"` // postgres only
+	Path     string `yaml:"path" env:"DB_PATH"`       // sqlite only
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// SlowQuery is the duration above which a query is logged as a warning
+	SlowQuery time.Duration `yaml:"slow_query" env:"DB_SLOW_QUERY"`
+}
+
+type JWT struct {
+	Secret          Secret        `yaml:"secret" env:"JWT_SECRET_KEY"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+}
+
+// SMTP configures outgoing mail; without a host, mails are only logged
+type SMTP struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	User     string `yaml:"user" env:"SMTP_USER"`
+	Password Secret `yaml:"password" env:"SMTP_PASS"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+type Login struct {
+	MaxAttempts      int           `yaml:"max_attempts" env:"LOGIN_MAX_ATTEMPTS"`
+	MaxAttemptsPerIP int           `yaml:"max_attempts_per_ip" env:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION"`
+	PasswordResetURL string        `yaml:"password_reset_url" env:"PASSWORD_RESET_URL"`
+	TOTPIssuer       string        `yaml:"totp_issuer" env:"TOTP_ISSUER"` // defaults to the workshop name
+
+	// Reset links that can be requested per email and per IP within an hour
+	MaxResetRequests      int `yaml:"max_reset_requests" env:"PASSWORD_RESET_MAX_REQUESTS"`
+	MaxResetRequestsPerIP int `yaml:"max_reset_requests_per_ip" env:"PASSWORD_RESET_MAX_REQUESTS_PER_IP"`
+}
+
+type Workshop struct {
+	Name           string  `yaml:"name" env:"WORKSHOP_NAME"`
+	InvoiceTaxRate float64 `yaml:"invoice_tax_rate" env:"INVOICE_TAX_RATE"` // percent, e.g. 11 for PPN 11%
+}
+
+type Scheduler struct {
+	PriceInterval time.Duration `yaml:"price_interval" env:"PRICE_SCHEDULER_INTERVAL"`
+}
+
+// Log configures the application log written to stdout
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn or error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json or text
+}
+
+// ParseLevel returns the slog level named by Level
+func (l Log) ParseLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(l.Level))
+	return level, err
+}
+
+// Metrics configures the Prometheus endpoint at /metrics
+type Metrics struct {
+	// Token must be sent as "Authorization: Bearer <token>" to scrape; without
+	// one the endpoint is disabled
+	Token Secret `yaml:"token" env:"METRICS_TOKEN"`
+}
+
+// Secret is a setting that must never be printed. It formats as "******" in
+// logs, JSON and YAML; Value returns the real content.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "******"
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(s.String())), nil
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// Default returns the settings used when nothing else is configured
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			DrainDelay:        5 * time.Second,
+		},
+		Database: Database{
+			Driver:          "mysql",
+			SSLMode:         "disable",
+			Path:            "bengkel.db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			SlowQuery:       200 * time.Millisecond,
+		},
+		JWT: JWT{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		SMTP: SMTP{Port: "25", From: "no-reply@bengkel.local"},
+		Login: Login{
+			MaxAttempts:      5,
+			MaxAttemptsPerIP: 20,
+			LockoutDuration:  15 * time.Minute,
+
+			MaxResetRequests:      3,
+			MaxResetRequestsPerIP: 10,
+		},
+		Scheduler: Scheduler{PriceInterval: time.Minute},
+		Log:       Log{Level: "info", Format: "json"},
+	}
+}
+
+// Load reads the configuration and validates it
+func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read reads the configuration without validating it, for commands that only
+// need part of it. The YAML file is taken from CONFIG_FILE, or config.yaml
+// when that exists.
+func Read() (*Config, error) {
+	// .env only fills variables that are not set in the environment yet
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading .env: %w", err)
+	}
+
+	cfg := Default()
+
+	file := os.Getenv("CONFIG_FILE")
+	if file == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			file = DefaultFile
+		}
+	}
+	if file != "" {
+		if err := cfg.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if cfg.Login.TOTPIssuer == "" {
+		cfg.Login.TOTPIssuer = cfg.Workshop.Name
+	}
+	if cfg.Login.TOTPIssuer == "" {
+		cfg.Login.TOTPIssuer = "Bengkel Inventory"
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return fmt.Errorf("parsing %s: %w", file, err)
+	}
+	return nil
+}
+
+// loadEnv overrides every field that has an env tag and a non-empty variable
+func loadEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := field.Tag.Get("env")
+		raw := os.Getenv(key)
+		if key == "" || raw == "" {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", key, raw, err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported setting type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "PORT must be a port number, got %q", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "SERVER_READ_TIMEOUT must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "SERVER_READ_HEADER_TIMEOUT must be positive")
+	check(c.Server.WriteTimeout > 0, "SERVER_WRITE_TIMEOUT must be positive")
+	check(c.Server.IdleTimeout > 0, "SERVER_IDLE_TIMEOUT must be positive")
+	check(c.Server.MaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+	check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+	check(c.Server.DrainDelay >= 0, "SERVER_DRAIN_DELAY must not be negative")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(net.ParseIP(proxy) != nil || cidrErr == nil, "SERVER_TRUSTED_PROXIES must list IPs or CIDRs, got %q", proxy)
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(c.JWT.Secret != "", "JWT_SECRET_KEY must be set")
+	check(c.JWT.AccessTokenTTL > 0, "ACCESS_TOKEN_TTL must be positive")
+	check(c.JWT.RefreshTokenTTL > 0, "REFRESH_TOKEN_TTL must be positive")
+
+	check(c.Login.MaxAttempts > 0, "LOGIN_MAX_ATTEMPTS must be positive")
+	check(c.Login.MaxAttemptsPerIP > 0, "LOGIN_MAX_ATTEMPTS_PER_IP must be positive")
+	check(c.Login.LockoutDuration > 0, "LOGIN_LOCKOUT_DURATION must be positive")
+	check(c.Login.MaxResetRequests > 0, "PASSWORD_RESET_MAX_REQUESTS must be positive")
+	check(c.Login.MaxResetRequestsPerIP > 0, "PASSWORD_RESET_MAX_REQUESTS_PER_IP must be positive")
+
+	check(c.Workshop.InvoiceTaxRate >= 0 && c.Workshop.InvoiceTaxRate <= 100,
+		"INVOICE_TAX_RATE must be between 0 and 100, got %v", c.Workshop.InvoiceTaxRate)
+	check(c.Scheduler.PriceInterval > 0, "PRICE_SCHEDULER_INTERVAL must be positive")
+
+	_, err = c.Log.ParseLevel()
+	check(err == nil, "LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT must be json or text, got %q", c.Log.Format)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate checks the database settings alone, for the migrate command
+func (d Database) Validate() error {
+	switch d.Driver {
+	case "mysql", "postgres":
+		if d.DSN == "" && (d.Host == "" || d.Name == "") {
+			return fmt.Errorf("DB_HOST and DB_NAME (or DB_DSN) must be set for %s", d.Driver)
+		}
+	case "sqlite":
+		if d.DSN == "" && d.Path == "" {
+			return errors.New("DB_PATH (or DB_DSN) must be set for sqlite")
+		}
+	default:
+		return fmt.Errorf("DB_DRIVER must be mysql, postgres or sqlite, got %q", d.Driver)
+	}
+	if d.MaxOpenConns < 1 || d.MaxIdleConns < 0 || d.MaxIdleConns > d.MaxOpenConns {
+		return fmt.Errorf("DB_MAX_OPEN_CONNS must be positive and DB_MAX_IDLE_CONNS between 0 and it, got %d and %d", d.MaxOpenConns, d.MaxIdleConns)
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 {
+		return errors.New("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME cannot be negative")
+	}
+	if d.SlowQuery < 0 {
+		return errors.New("DB_SLOW_QUERY cannot be negative")
+	}
+	return nil
+}
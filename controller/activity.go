@@ -3,23 +3,35 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/utils"
 	"github.com/sinscostank/bengkel-inventory/service"
 
 )
 
+// ActivityMetrics counts created and failed activities for the metrics endpoint
+type ActivityMetrics interface {
+	ActivityCreated(activityType string, quantity int)
+	ActivityFailed(activityType, reason string)
+}
+
 // ActivityController struct will hold the repository instance
 type ActivityController struct {
 	ActivityService service.ActivityService
+	InvoiceService  service.InvoiceService
+	Metrics         ActivityMetrics
 }
 
 // NewActivityController creates a new ActivityController instance
-func NewActivityController(ActivityService service.ActivityService) *ActivityController {
+func NewActivityController(ActivityService service.ActivityService, InvoiceService service.InvoiceService, metrics ActivityMetrics) *ActivityController {
 	return &ActivityController{
 		ActivityService: ActivityService,
+		InvoiceService:  InvoiceService,
+		Metrics:         metrics,
 	}
 }
 
@@ -27,13 +39,9 @@ func NewActivityController(ActivityService service.ActivityService) *ActivityCon
 func (pc *ActivityController) GetActivities(c *gin.Context) {
 
 	// Get all categories from the repository
-	acts, err := pc.ActivityService.GetAll()
+	acts, err := pc.ActivityService.GetAll(c.Request.Context())
 	if err != nil {
-		if err.Error() == "activity not found" {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -43,64 +51,92 @@ func (pc *ActivityController) GetActivities(c *gin.Context) {
 	})
 }
 
-// CreateActivity adds a new product
+// CreateActivity records a sale or a stock adjustment. Every outcome is
+// counted for the metrics endpoint, rejected requests by their error code.
 func (pc *ActivityController) CreateActivity(c *gin.Context) {
 	var req forms.ActivityForm
 
 	// Determine type based on route
+	activityType := "unknown"
 	switch c.FullPath() {
 	case "/activities":
-		req.Type = "outbound"
+		activityType = "outbound"
 	case "/stock-transactions":
-		req.Type = "inbound"
+		activityType = "inbound"
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route"})
+		pc.fail(c, activityType, apperror.Validation("invalid_route", "Invalid route"))
 		return
 	}
+	req.Type = activityType
 
 	claimsRaw, exists := c.Get("userClaims")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		pc.fail(c, activityType, apperror.Unauthorized("unauthenticated", "User not authenticated"))
 		return
 	}
 	userClaims := claimsRaw.(*utils.UserClaims)
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pc.fail(c, activityType, apperror.InvalidRequest(err))
 		return
 	}
 
-	activity, err := pc.ActivityService.Create(userClaims.ID, userClaims.Role, &req)
+	activity, err := pc.ActivityService.Create(c.Request.Context(), userClaims, &req)
 	if err != nil {
-		if err.Error() == "invalid activity type" || err.Error() == "duplicate product ID found" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else if err.Error() == "only admins can create inbound activities" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		pc.fail(c, activityType, err)
 		return
 	}
 
+	quantity := 0
+	for _, item := range req.Products {
+		quantity += int(item.Quantity)
+	}
+	pc.Metrics.ActivityCreated(activity.Type, quantity)
+
 	c.JSON(http.StatusCreated, activity)
 }
 
+// fail counts a rejected activity by its error code and answers with err
+func (pc *ActivityController) fail(c *gin.Context, activityType string, err error) {
+	reason := "internal_error"
+	if appErr, ok := apperror.As(err); ok {
+		reason = appErr.Code
+	}
+	pc.Metrics.ActivityFailed(activityType, reason)
+	c.Error(err)
+}
+
 func (pc *ActivityController) GetActivityByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Activity ID"})
+		c.Error(apperror.Validation("invalid_activity_id", "Invalid Activity ID"))
 		return
 	}
 
-	activity, err := pc.ActivityService.GetByID(uint(id))
+	activity, err := pc.ActivityService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		if err.Error() == "activity not found" {
-			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, activity)
-}
\ No newline at end of file
+}
+
+// GetInvoice renders the PDF invoice of a sale
+func (pc *ActivityController) GetInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_activity_id", "Invalid Activity ID"))
+		return
+	}
+
+	pdf, number, err := pc.InvoiceService.RenderPDF(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := strings.ReplaceAll(number, "/", "-") + ".pdf"
+	c.Header("Content-Disposition", "inline; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
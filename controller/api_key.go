@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// APIKeyController lets admins manage the API keys used by integrations
+type APIKeyController struct {
+	APIKeyService service.APIKeyService
+}
+
+// NewAPIKeyController creates a new APIKeyController instance
+func NewAPIKeyController(apiKeyService service.APIKeyService) *APIKeyController {
+	return &APIKeyController{APIKeyService: apiKeyService}
+}
+
+// GetAPIKeys returns all API keys, without their secrets
+func (kc *APIKeyController) GetAPIKeys(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	keys, total, err := kc.APIKeyService.GetAll(c.Request.Context(), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         keys,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// CreateAPIKey creates a key. The raw key is only returned in this response.
+func (kc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var req forms.APIKeyForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	key, rawKey, err := kc.APIKeyService.Create(c.Request.Context(), actorID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": key,
+		"key":  rawKey,
+	})
+}
+
+// RevokeAPIKey disables a key immediately
+func (kc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_api_key_id", "Invalid API key ID"))
+		return
+	}
+
+	if err := kc.APIKeyService.Revoke(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
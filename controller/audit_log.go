@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// AuditLogController exposes the audit log to admins
+type AuditLogController struct {
+	AuditService service.AuditService
+}
+
+// NewAuditLogController creates a new AuditLogController instance
+func NewAuditLogController(auditService service.AuditService) *AuditLogController {
+	return &AuditLogController{AuditService: auditService}
+}
+
+// GetAuditLogs returns audit entries filtered by entity, user and date
+func (ac *AuditLogController) GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	var query forms.AuditLogQueryForm
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	logs, total, err := ac.AuditService.Search(c.Request.Context(), query, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         logs,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// auditBefore hands the current state of an entity to the audit middleware
+// before a handler changes it
+func auditBefore(c *gin.Context, before interface{}) {
+	if before != nil {
+		c.Set(utils.AuditBeforeKey, before)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/service"
 )
@@ -31,9 +32,9 @@ func (cc *CategoryController) GetCategories(c *gin.Context) {
 		limit = 10
 	}
 
-	cats, total, err := cc.CategoryService.GetAll(page, limit)
+	cats, total, err := cc.CategoryService.GetAll(c.Request.Context(), page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -51,13 +52,13 @@ func (cc *CategoryController) GetCategories(c *gin.Context) {
 func (cc *CategoryController) CreateCategory(c *gin.Context) {
 	var req forms.CategoryForm
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	category, err := cc.CategoryService.Create(&req)
+	category, err := cc.CategoryService.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		c.Error(err)
 		return
 	}
 
@@ -67,17 +68,13 @@ func (cc *CategoryController) CreateCategory(c *gin.Context) {
 func (cc *CategoryController) GetCategoryByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
 		return
 	}
 
-	category, err := cc.CategoryService.GetByID(uint(id))
+	category, err := cc.CategoryService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		if err.Error() == "category not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -88,22 +85,22 @@ func (cc *CategoryController) UpdateCategory(c *gin.Context) {
 	var req forms.CategoryForm
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
 		return
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	category, err := cc.CategoryService.Update(uint(id), &req)
+	if before, err := cc.CategoryService.GetByID(c.Request.Context(), uint(id)); err == nil {
+		auditBefore(c, before)
+	}
+
+	category, err := cc.CategoryService.Update(c.Request.Context(), uint(id), &req)
 	if err != nil {
-		if err.Error() == "category not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -113,12 +110,16 @@ func (cc *CategoryController) UpdateCategory(c *gin.Context) {
 func (cc *CategoryController) DeleteCategory(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
 		return
 	}
 
-	if err := cc.CategoryService.Delete(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+	if before, err := cc.CategoryService.GetByID(c.Request.Context(), uint(id)); err == nil {
+		auditBefore(c, before)
+	}
+
+	if err := cc.CategoryService.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -126,3 +127,83 @@ func (cc *CategoryController) DeleteCategory(c *gin.Context) {
 		"status": "success",
 	})
 }
+
+// GetCategoryTree returns the whole category hierarchy
+func (cc *CategoryController) GetCategoryTree(c *gin.Context) {
+	tree, err := cc.CategoryService.GetTree(c.Request.Context(), 0)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+// GetCategorySubtree returns a category with all its subcategories
+func (cc *CategoryController) GetCategorySubtree(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
+		return
+	}
+
+	tree, err := cc.CategoryService.GetTree(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree[0])
+}
+
+// MoveCategory changes the parent of a category
+func (cc *CategoryController) MoveCategory(c *gin.Context) {
+	var req forms.MoveCategoryForm
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if before, err := cc.CategoryService.GetByID(c.Request.Context(), uint(id)); err == nil {
+		auditBefore(c, before)
+	}
+
+	category, err := cc.CategoryService.Move(c.Request.Context(), uint(id), req.ParentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// CategorySalesReport returns sales rolled up to a category level (?level=0 for top-level categories)
+func (cc *CategoryController) CategorySalesReport(c *gin.Context) {
+	level, err := strconv.Atoi(c.DefaultQuery("level", "0"))
+	if err != nil || level < 0 {
+		c.Error(apperror.Validation("invalid_level", "Invalid level"))
+		return
+	}
+	rootID, err := strconv.Atoi(c.DefaultQuery("category_id", "0"))
+	if err != nil || rootID < 0 {
+		c.Error(apperror.Validation("invalid_category_id", "Invalid category ID"))
+		return
+	}
+
+	report, err := cc.CategoryService.GetSalesRollup(c.Request.Context(), level, uint(rootID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  report,
+		"level": level,
+	})
+}
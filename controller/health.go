@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/health"
+)
+
+// HealthController answers the probes of load balancers, orchestrators and
+// the Docker healthcheck
+type HealthController struct {
+	Checker *health.Checker
+}
+
+// NewHealthController creates a new HealthController instance
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{
+		Checker: checker,
+	}
+}
+
+// Live answers as long as the process serves requests; it checks no dependencies
+func (hc *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready checks the database, the migrations and the background workers, and
+// answers 503 while any of them fails or the server is starting or shutting down
+func (hc *HealthController) Ready(c *gin.Context) {
+	report, ok := hc.Checker.Ready(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// PasswordController handles password change and recovery
+type PasswordController struct {
+	PasswordService service.PasswordService
+}
+
+// NewPasswordController creates a new PasswordController instance
+func NewPasswordController(passwordService service.PasswordService) *PasswordController {
+	return &PasswordController{PasswordService: passwordService}
+}
+
+// ChangePassword changes the password of the logged-in user
+func (pc *PasswordController) ChangePassword(c *gin.Context) {
+	var req forms.ChangePasswordForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	claims := c.MustGet("userClaims").(*utils.UserClaims)
+	if err := pc.PasswordService.ChangePassword(c.Request.Context(), claims.ID, claims.SessionID, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password changed",
+	})
+}
+
+// ForgotPassword emails a reset link. The response is the same whether or not the email exists,
+// and 429 once the email or IP requested too many links.
+func (pc *PasswordController) ForgotPassword(c *gin.Context) {
+	var req forms.ForgotPasswordForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if err := pc.PasswordService.RequestReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password using an emailed reset token
+func (pc *PasswordController) ResetPassword(c *gin.Context) {
+	var req forms.ResetPasswordForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	user, err := pc.PasswordService.ResetPassword(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Set(utils.AuditActorKey, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password has been reset",
+	})
+}
+
+// SendResetLink lets an admin email a reset link to a user
+func (pc *PasswordController) SendResetLink(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := pc.PasswordService.SendResetLink(c.Request.Context(), actorID(c), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Reset link sent",
+	})
+}
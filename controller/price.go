@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// PriceController exposes the price history and scheduled price changes of products
+type PriceController struct {
+	PriceService service.PriceService
+}
+
+// NewPriceController creates a new PriceController instance
+func NewPriceController(priceService service.PriceService) *PriceController {
+	return &PriceController{PriceService: priceService}
+}
+
+// GetPriceHistory returns the price changes of a product, newest first
+func (pc *PriceController) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	history, total, err := pc.PriceService.GetHistory(c.Request.Context(), uint(id), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         history,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// GetScheduledPrices returns the scheduled price changes of a product (?status=pending|applied|cancelled)
+func (pc *PriceController) GetScheduledPrices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != "pending" && status != "applied" && status != "cancelled" {
+		c.Error(apperror.Validation("invalid_status", "Invalid status"))
+		return
+	}
+
+	changes, err := pc.PriceService.GetScheduled(c.Request.Context(), uint(id), status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+// SchedulePrice plans a future price change for a product
+func (pc *PriceController) SchedulePrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
+		return
+	}
+
+	var req forms.ScheduledPriceForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	change, err := pc.PriceService.Schedule(c.Request.Context(), actorID(c), uint(id), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, change)
+}
+
+// CancelScheduledPrice withdraws a pending price change
+func (pc *PriceController) CancelScheduledPrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_scheduled_price_change_id", "Invalid scheduled price change ID"))
+		return
+	}
+
+	if err := pc.PriceService.CancelScheduled(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// BulkUpdatePrices shifts the price of many products at once (dry_run previews the result)
+func (pc *PriceController) BulkUpdatePrices(c *gin.Context) {
+	var req forms.BulkPriceForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	result, err := pc.PriceService.BulkUpdate(c.Request.Context(), actorID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
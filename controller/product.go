@@ -6,6 +6,7 @@ import (
 	"math"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/service"
 )
@@ -22,7 +23,7 @@ func NewProductController(productService service.ProductService) *ProductControl
 	}
 }
 
-// GetProducts returns all products
+// GetProducts returns all products, optionally searched, filtered and sorted
 func (pc *ProductController) GetProducts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -34,9 +35,15 @@ func (pc *ProductController) GetProducts(c *gin.Context) {
 		limit = 10
 	}
 
-	prods, total, err := pc.ProductService.GetAll(page, limit)
+	var query forms.ProductQueryForm
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	prods, total, err := pc.ProductService.GetAll(c.Request.Context(), query, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -55,13 +62,13 @@ func (pc *ProductController) GetProducts(c *gin.Context) {
 func (pc *ProductController) CreateProduct(c *gin.Context) {
 	var req forms.ProductForm
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	product, err := pc.ProductService.Create(req)
+	product, err := pc.ProductService.Create(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -73,17 +80,13 @@ func (pc *ProductController) GetProductByID(c *gin.Context) {
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
 		return
 	}
 
-	product, err := pc.ProductService.GetByID(uint(id))
+	product, err := pc.ProductService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		if err.Error() == "product not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -95,13 +98,19 @@ func (pc *ProductController) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 	var req forms.UpdateProductForm
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	product, err := pc.ProductService.Update(id, req)
+	if pid, err := strconv.Atoi(id); err == nil {
+		if before, err := pc.ProductService.GetByID(c.Request.Context(), uint(pid)); err == nil {
+			auditBefore(c, before)
+		}
+	}
+
+	product, err := pc.ProductService.Update(c.Request.Context(), actorID(c), id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, product)
@@ -110,8 +119,14 @@ func (pc *ProductController) UpdateProduct(c *gin.Context) {
 // DeleteProduct deletes a product by ID
 func (pc *ProductController) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
-	if err := pc.ProductService.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if pid, err := strconv.Atoi(id); err == nil {
+		if before, err := pc.ProductService.GetByID(c.Request.Context(), uint(pid)); err == nil {
+			auditBefore(c, before)
+		}
+	}
+
+	if err := pc.ProductService.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 	
@@ -132,9 +147,9 @@ func (pc *ProductController) SalesReport(c *gin.Context) {
 		limit = 10
 	}
 
-	report, total, err := pc.ProductService.GetSalesReport(page, limit)
+	report, total, err := pc.ProductService.GetSalesReport(c.Request.Context(), page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sales report"})
+		c.Error(err)
 		return
 	}
 
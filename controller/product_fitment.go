@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// ProductFitmentController struct holds the service instance
+type ProductFitmentController struct {
+	FitmentService service.ProductFitmentService
+}
+
+// NewProductFitmentController creates a new ProductFitmentController instance
+func NewProductFitmentController(fitmentService service.ProductFitmentService) *ProductFitmentController {
+	return &ProductFitmentController{
+		FitmentService: fitmentService,
+	}
+}
+
+// GetProductFitments returns the vehicles a product fits
+func (fc *ProductFitmentController) GetProductFitments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
+		return
+	}
+
+	fitments, err := fc.FitmentService.GetByProduct(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": fitments})
+}
+
+// CreateProductFitment links a product to a vehicle make/model/year range
+func (fc *ProductFitmentController) CreateProductFitment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_product_id", "Invalid product ID"))
+		return
+	}
+
+	var req forms.FitmentForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	fitment, err := fc.FitmentService.Create(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, fitment)
+}
+
+// DeleteFitment removes a fitment
+func (fc *ProductFitmentController) DeleteFitment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_fitment_id", "Invalid fitment ID"))
+		return
+	}
+
+	if err := fc.FitmentService.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
+// SearchCompatibleProducts returns the parts fitting a vehicle
+func (fc *ProductFitmentController) SearchCompatibleProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	var req forms.FitmentSearchForm
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	prods, total, err := fc.FitmentService.Search(c.Request.Context(), &req, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         prods,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// ImportFitments bulk-imports fitments from an uploaded CSV file
+func (fc *ProductFitmentController) ImportFitments(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(apperror.Validation("csv_required", "CSV file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	result, err := fc.FitmentService.ImportCSV(c.Request.Context(), file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// ReceiptController struct holds the service instance
+type ReceiptController struct {
+	ReceiptService service.ReceiptService
+}
+
+// NewReceiptController creates a new ReceiptController instance
+func NewReceiptController(receiptService service.ReceiptService) *ReceiptController {
+	return &ReceiptController{
+		ReceiptService: receiptService,
+	}
+}
+
+// GetReceipt renders an activity as an ESC/POS byte stream, or as plain text with ?format=text
+func (rc *ReceiptController) GetReceipt(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_activity_id", "Invalid Activity ID"))
+		return
+	}
+
+	paper, err := strconv.Atoi(c.DefaultQuery("paper", "58"))
+	if err != nil {
+		c.Error(apperror.Validation("invalid_paper_width", "Invalid paper width"))
+		return
+	}
+
+	paid, err := strconv.ParseFloat(c.DefaultQuery("paid", "0"), 64)
+	if err != nil || paid < 0 || math.IsNaN(paid) || math.IsInf(paid, 0) {
+		c.Error(apperror.Validation("invalid_paid_amount", "Invalid paid amount"))
+		return
+	}
+
+	receipt, err := rc.ReceiptService.Render(c.Request.Context(), uint(id), c.Query("branch"), paper, paid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	switch c.DefaultQuery("format", "escpos") {
+	case "text":
+		c.String(http.StatusOK, receipt.Text())
+	case "escpos":
+		c.Header("Content-Disposition", "attachment; filename=receipt-"+c.Param("id")+".bin")
+		c.Data(http.StatusOK, "application/octet-stream", receipt.ESCPOS())
+	default:
+		c.Error(apperror.Validation("invalid_format", "Invalid format"))
+	}
+}
+
+// GetTemplate returns the receipt header/footer of a branch
+func (rc *ReceiptController) GetTemplate(c *gin.Context) {
+	template, err := rc.ReceiptService.GetTemplate(c.Request.Context(), c.Param("branch"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate creates or replaces the receipt header/footer of a branch
+func (rc *ReceiptController) UpdateTemplate(c *gin.Context) {
+	var req forms.ReceiptTemplateForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if before, err := rc.ReceiptService.GetTemplate(c.Request.Context(), c.Param("branch")); err == nil {
+		auditBefore(c, before)
+	}
+
+	template, err := rc.ReceiptService.SaveTemplate(c.Request.Context(), c.Param("branch"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// RoleController manages roles and their permissions at runtime
+type RoleController struct {
+	PermissionService service.PermissionService
+}
+
+// NewRoleController creates a new RoleController instance
+func NewRoleController(permissionService service.PermissionService) *RoleController {
+	return &RoleController{PermissionService: permissionService}
+}
+
+// GetPermissions returns the catalogue of permissions
+func (rc *RoleController) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": models.AllPermissions})
+}
+
+// GetRoles returns all roles with their permissions
+func (rc *RoleController) GetRoles(c *gin.Context) {
+	roles, err := rc.PermissionService.GetRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// CreateRole adds a new role
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var req forms.RoleForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	role, err := rc.PermissionService.CreateRole(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRolePermissions replaces the permissions of a role
+func (rc *RoleController) UpdateRolePermissions(c *gin.Context) {
+	var req forms.RolePermissionsForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	role, err := rc.PermissionService.UpdateRolePermissions(c.Request.Context(), c.Param("name"), req.Permissions)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// UpdateRoleTwoFactor makes TOTP mandatory or optional for users of a role
+func (rc *RoleController) UpdateRoleTwoFactor(c *gin.Context) {
+	var req forms.RoleTwoFactorForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	role, err := rc.PermissionService.SetTwoFactorRequired(c.Request.Context(), c.Param("name"), *req.Required)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole removes a role that no user holds
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	if err := rc.PermissionService.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// SupplierController struct holds the service instance
+type SupplierController struct {
+	SupplierService service.SupplierService
+}
+
+// NewSupplierController creates a new SupplierController instance
+func NewSupplierController(supplierService service.SupplierService) *SupplierController {
+	return &SupplierController{
+		SupplierService: supplierService,
+	}
+}
+
+// GetSuppliers returns the registered suppliers
+func (sc *SupplierController) GetSuppliers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	suppliers, total, err := sc.SupplierService.GetAll(c.Request.Context(), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         suppliers,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// GetSupplierByID returns a registered supplier
+func (sc *SupplierController) GetSupplierByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_supplier_id", "Invalid supplier ID"))
+		return
+	}
+
+	supplier, err := sc.SupplierService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+// CreateSupplier registers a supplier
+func (sc *SupplierController) CreateSupplier(c *gin.Context) {
+	var req forms.SupplierForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	supplier, err := sc.SupplierService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, supplier)
+}
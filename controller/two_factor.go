@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// TwoFactorController handles TOTP enrollment and the second login step
+type TwoFactorController struct {
+	TwoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorController creates a new TwoFactorController instance
+func NewTwoFactorController(twoFactorService service.TwoFactorService) *TwoFactorController {
+	return &TwoFactorController{TwoFactorService: twoFactorService}
+}
+
+// VerifyLogin exchanges a login challenge and a TOTP or recovery code for tokens
+func (tc *TwoFactorController) VerifyLogin(c *gin.Context) {
+	var req forms.TwoFactorLoginForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	result, err := tc.TwoFactorService.VerifyChallenge(c.Request.Context(), req.ChallengeToken, req.Code, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var extra gin.H
+	if result.RecoveryCodes != nil {
+		extra = gin.H{"recovery_codes": result.RecoveryCodes}
+	}
+	respondLogin(c, result.Tokens, result.User, extra)
+}
+
+// EnrollLogin starts TOTP enrollment for a user whose role requires it and who is logging in
+func (tc *TwoFactorController) EnrollLogin(c *gin.Context) {
+	var req forms.LoginChallengeForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	enrollment, err := tc.TwoFactorService.EnrollChallenge(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": enrollment})
+}
+
+// Enroll generates a TOTP secret for the logged-in user
+func (tc *TwoFactorController) Enroll(c *gin.Context) {
+	enrollment, err := tc.TwoFactorService.Enroll(c.Request.Context(), actorID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": enrollment})
+}
+
+// Confirm enables 2FA with a first valid code and returns the recovery codes
+func (tc *TwoFactorController) Confirm(c *gin.Context) {
+	var req forms.TwoFactorCodeForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	codes, err := tc.TwoFactorService.Confirm(c.Request.Context(), actorID(c), req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable turns 2FA off for the logged-in user
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	var req forms.DisableTwoFactorForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if err := tc.TwoFactorService.Disable(c.Request.Context(), actorID(c), req.Password, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the logged-in user
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req forms.TwoFactorCodeForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	codes, err := tc.TwoFactorService.RegenerateRecoveryCodes(c.Request.Context(), actorID(c), req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// ResetUser removes 2FA from a user who lost their authenticator
+func (tc *TwoFactorController) ResetUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.TwoFactorService.Reset(c.Request.Context(), actorID(c), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
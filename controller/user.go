@@ -4,17 +4,21 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/service"
+	"github.com/sinscostank/bengkel-inventory/utils"
 )
 
 // UserController contains the repository for database access
 type UserController struct {
-	UserService service.UserService
+	UserService    service.UserService
+	SessionService service.SessionService
 }
 
-func NewUserController(service service.UserService) *UserController {
-	return &UserController{UserService: service}
+func NewUserController(service service.UserService, sessionService service.SessionService) *UserController {
+	return &UserController{UserService: service, SessionService: sessionService}
 }
 
 // RegisterUser handles user registration
@@ -22,30 +26,16 @@ func (uc *UserController) RegisterUser(c *gin.Context) {
 	var req forms.RegisterForm
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Invalid input data",
-			"details": err.Error(),
-		})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	err := uc.UserService.Register(&req)
+	user, err := uc.UserService.Register(c.Request.Context(), &req)
 	if err != nil {
-		if err.Error() == "email already in use" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": err.Error(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to register user",
-			"details": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
+	c.Set(utils.AuditActorKey, user.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  "success",
@@ -58,42 +48,103 @@ func (uc *UserController) LoginUser(c *gin.Context) {
 	var req forms.LoginForm
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Invalid input data",
-			"details": err.Error(),
-		})
+		c.Error(apperror.InvalidRequest(err))
 		return
 	}
 
-	token, user, err := uc.UserService.Login(&req)
+	result, err := uc.UserService.Login(c.Request.Context(), &req, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		if err.Error() == "invalid credentials" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": err.Error(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to login",
-			"details": err.Error(),
+		c.Error(err)
+		return
+	}
+
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Two-factor authentication required",
+			"data":    result.Challenge,
 		})
 		return
 	}
 
+	respondLogin(c, result.Tokens, result.User, nil)
+}
+
+// respondLogin writes the token pair and profile of a user who just logged in
+func respondLogin(c *gin.Context, tokens *service.TokenPair, user *models.User, extra gin.H) {
+	data := gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user": gin.H{
+			"id":                  user.ID,
+			"name":                user.Name,
+			"email":               user.Email,
+			"role":                user.Role,
+			"must_reset_password": user.MustResetPassword,
+			"totp_enabled":        user.TOTPEnabled,
+		},
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Login successful",
-		"data": gin.H{
-			"token": token,
-			"user": gin.H{
-				"id":    user.ID,
-				"name":  user.Name,
-				"email": user.Email,
-				"role":  user.Role,
-			},
-		},
+		"data":    data,
+	})
+}
+
+// RefreshToken exchanges a refresh token for a new access/refresh token pair
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var req forms.RefreshTokenForm
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	tokens, user, err := uc.SessionService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Set(utils.AuditActorKey, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Token refreshed",
+		"data":    tokens,
 	})
-}
\ No newline at end of file
+}
+
+// Logout revokes the current session
+func (uc *UserController) Logout(c *gin.Context) {
+	claims := c.MustGet("userClaims").(*utils.UserClaims)
+
+	if err := uc.SessionService.Logout(c.Request.Context(), claims.SessionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Logged out",
+	})
+}
+
+// LogoutAll revokes every session of the current user
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	claims := c.MustGet("userClaims").(*utils.UserClaims)
+
+	if err := uc.SessionService.LogoutAll(c.Request.Context(), claims.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Logged out from all sessions",
+	})
+}
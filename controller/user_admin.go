@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// UserAdminController exposes the admin-only user management endpoints
+type UserAdminController struct {
+	UserAdminService service.UserAdminService
+}
+
+// NewUserAdminController creates a new UserAdminController instance
+func NewUserAdminController(userAdminService service.UserAdminService) *UserAdminController {
+	return &UserAdminController{UserAdminService: userAdminService}
+}
+
+// GetUsers returns all users
+func (uc *UserAdminController) GetUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	users, total, err := uc.UserAdminService.GetAll(c.Request.Context(), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         users,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// GetUserByID returns a user
+func (uc *UserAdminController) GetUserByID(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := uc.UserAdminService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// CreateUser creates a user with any role
+func (uc *UserAdminController) CreateUser(c *gin.Context) {
+	var req forms.CreateUserForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	user, err := uc.UserAdminService.Create(c.Request.Context(), actorID(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser changes the name and email of a user
+func (uc *UserAdminController) UpdateUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req forms.UpdateUserForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if before, err := uc.UserAdminService.GetByID(c.Request.Context(), id); err == nil {
+		auditBefore(c, before)
+	}
+
+	user, err := uc.UserAdminService.Update(c.Request.Context(), actorID(c), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangeUserRole promotes or demotes a user
+func (uc *UserAdminController) ChangeUserRole(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req forms.ChangeRoleForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	if before, err := uc.UserAdminService.GetByID(c.Request.Context(), id); err == nil {
+		auditBefore(c, before)
+	}
+
+	user, err := uc.UserAdminService.ChangeRole(c.Request.Context(), actorID(c), id, req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeactivateUser blocks a user from logging in
+func (uc *UserAdminController) DeactivateUser(c *gin.Context) {
+	uc.setActive(c, false)
+}
+
+// ReactivateUser allows a deactivated user to log in again
+func (uc *UserAdminController) ReactivateUser(c *gin.Context) {
+	uc.setActive(c, true)
+}
+
+func (uc *UserAdminController) setActive(c *gin.Context, active bool) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if before, err := uc.UserAdminService.GetByID(c.Request.Context(), id); err == nil {
+		auditBefore(c, before)
+	}
+
+	user, err := uc.UserAdminService.SetActive(c.Request.Context(), actorID(c), id, active)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ForcePasswordReset sets a temporary password the user must change on next login
+func (uc *UserAdminController) ForcePasswordReset(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	tempPassword, err := uc.UserAdminService.ForcePasswordReset(c.Request.Context(), actorID(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":             "success",
+		"temporary_password": tempPassword,
+	})
+}
+
+// GetUserAuditTrail returns the administrative changes made to a user
+func (uc *UserAdminController) GetUserAuditTrail(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	logs, err := uc.UserAdminService.GetAuditTrail(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": logs})
+}
+
+// UnlockUser lifts a login lockout caused by failed attempts
+func (uc *UserAdminController) UnlockUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := uc.UserAdminService.Unlock(c.Request.Context(), actorID(c), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// GetUserLogins returns the login attempts of a user
+func (uc *UserAdminController) GetUserLogins(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	attempts, total, err := uc.UserAdminService.GetLoginHistory(c.Request.Context(), id, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         attempts,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+func userIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_user_id", "Invalid user ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// actorID returns the ID of the authenticated user
+func actorID(c *gin.Context) uint {
+	if claims, ok := c.MustGet("userClaims").(*utils.UserClaims); ok {
+		return claims.ID
+	}
+	return 0
+}
+
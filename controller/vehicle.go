@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/service"
+)
+
+// VehicleController struct holds the service instance
+type VehicleController struct {
+	VehicleService service.VehicleService
+}
+
+// NewVehicleController creates a new VehicleController instance
+func NewVehicleController(vehicleService service.VehicleService) *VehicleController {
+	return &VehicleController{
+		VehicleService: vehicleService,
+	}
+}
+
+// GetVehicles returns the registered vehicles
+func (vc *VehicleController) GetVehicles(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	vehicles, total, err := vc.VehicleService.GetAll(c.Request.Context(), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         vehicles,
+		"current_page": page,
+		"limit":        limit,
+		"total_items":  total,
+		"total_pages":  totalPages,
+	})
+}
+
+// GetVehicleByID returns a registered vehicle
+func (vc *VehicleController) GetVehicleByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperror.Validation("invalid_vehicle_id", "Invalid vehicle ID"))
+		return
+	}
+
+	vehicle, err := vc.VehicleService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vehicle)
+}
+
+// CreateVehicle registers a customer vehicle
+func (vc *VehicleController) CreateVehicle(c *gin.Context) {
+	var req forms.VehicleForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.InvalidRequest(err))
+		return
+	}
+
+	vehicle, err := vc.VehicleService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, vehicle)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [n]        roll back the last n migrations (default 1)
+  status          list migrations and whether they are applied
+  check           compare the database schema with the models
+  baseline <n>    mark migrations up to n as applied without running them`
+
+// MigrateCommand runs the `migrate` subcommand of the binary
+func MigrateCommand(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		if err := SeedRoles(db); err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New("down expects a positive number of migrations")
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back %d migration(s)\n", rolledBack)
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "-"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %-9s %s\n", s.Version, s.Name, s.State, appliedAt)
+		}
+
+	case "check":
+		problems, err := CheckSchema(db)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("schema differs from the models in %d place(s)", len(problems))
+		}
+		fmt.Println("schema matches the models")
+
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New("baseline expects a migration version")
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return errors.New("baseline expects a migration version")
+		}
+		if err := migrator.Baseline(uint(version)); err != nil {
+			return err
+		}
+		fmt.Printf("recorded migrations up to %04d as applied\n", version)
+
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
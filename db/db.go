@@ -3,37 +3,96 @@ package db
 
 import (
 	"fmt"
-	"os"
-	"log"
+	"log/slog"
 
-	"github.com/joho/godotenv"
+	"github.com/glebarez/sqlite"
+	"github.com/sinscostank/bengkel-inventory/config"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// InitDB connects to the database and applies the pending migrations
+func InitDB(cfg config.Database) (*gorm.DB, error) {
+    db, err := Connect(cfg)
+    if err != nil {
+        return nil, err
+    }
 
-func InitDB() (*gorm.DB, error) {
-    if err := godotenv.Load(); err != nil {
-        log.Println("No .env file found. Falling back to OS environment variables.")
+    if err := RunMigration(db); err != nil {
+        return nil, err
     }
 
-    dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-        os.Getenv("DB_USER"),
-        os.Getenv("DB_PASS"),
-        os.Getenv("DB_HOST"),
-        os.Getenv("DB_PORT"),
-        os.Getenv("DB_NAME"),
-    )
-    fmt.Println("▶️ Connecting to database with DSN:", dsn)
+    return db, nil
+}
+
+// Connect opens the database connection without migrating
+func Connect(cfg config.Database) (*gorm.DB, error) {
+    dialector, err := dialector(cfg)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.Driver == "sqlite" {
+        slog.Info("connecting to the database", "driver", cfg.Driver, "path", cfg.Path)
+    } else {
+        slog.Info("connecting to the database", "driver", cfg.Driver, "name", cfg.Name, "host", cfg.Host, "port", cfg.Port)
+    }
 
-    db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+    db, err := gorm.Open(dialector, &gorm.Config{Logger: queryLogger{slowQuery: cfg.SlowQuery}})
     if err != nil {
         return nil, fmt.Errorf("failed to connect to database: %w", err)
     }
 
-    RunMigration(db)
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+    sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+    sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+    sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-    DB = db
     return db, nil
 }
+
+// dialector picks the driver (mysql, postgres or sqlite). A DSN overrides the
+// connection string built from the other settings; SQLite only needs a path.
+func dialector(cfg config.Database) (gorm.Dialector, error) {
+    dsn := cfg.DSN.Value()
+
+    switch cfg.Driver {
+    case "mysql":
+        if dsn == "" {
+            dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+                cfg.User,
+                cfg.Password.Value(),
+                cfg.Host,
+                cfg.Port,
+                cfg.Name,
+            )
+        }
+        return mysql.Open(dsn), nil
+
+    case "postgres":
+        if dsn == "" {
+            dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+                cfg.Host,
+                cfg.Port,
+                cfg.User,
+                cfg.Password.Value(),
+                cfg.Name,
+                cfg.SSLMode,
+            )
+        }
+        return postgres.Open(dsn), nil
+
+    case "sqlite":
+        if dsn == "" {
+            // Foreign keys are off by default in SQLite; the busy timeout lets
+            // concurrent writers wait instead of failing with "database is locked"
+            dsn = cfg.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+        }
+        return sqlite.Open(dsn), nil
+    }
+    return nil, fmt.Errorf("unsupported DB_DRIVER %q (use mysql, postgres or sqlite)", cfg.Driver)
+}
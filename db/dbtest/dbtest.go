@@ -0,0 +1,37 @@
+// Package dbtest opens migrated SQLite databases for tests.
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/db"
+	"gorm.io/gorm"
+)
+
+// Open creates a fresh SQLite database in a temporary directory, applies the
+// migrations and seeds the built-in roles. The database is closed and removed
+// when the test ends.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	cfg := config.Default().Database
+	cfg.Driver = "sqlite"
+	cfg.Path = filepath.Join(t.TempDir(), "test.db")
+
+	conn, err := db.Connect(cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.RunMigration(conn); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return conn
+}
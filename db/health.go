@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sinscostank/bengkel-inventory/health"
+	"gorm.io/gorm"
+)
+
+// PingCheck reports whether the database answers
+func PingCheck(conn *gorm.DB) health.Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// MigrationCheck reports whether the schema is at the version this binary expects
+func MigrationCheck(conn *gorm.DB) (health.Check, error) {
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		pending, err := migrator.withContext(ctx).Pending()
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pending migration(s)", pending)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryLogger sends GORM's logs to slog with the context of the query, so
+// repositories that pass the request context log its request ID. Statements
+// are logged at debug, slow ones as warnings and failures as errors, always
+// without their parameters, which may hold password hashes or tokens.
+type queryLogger struct {
+	slowQuery time.Duration
+}
+
+var _ logger.Interface = queryLogger{}
+var _ gorm.ParamsFilter = queryLogger{}
+
+func (l queryLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, "gorm: "+msg, "args", args)
+}
+
+func (l queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, "gorm: "+msg, "args", args)
+}
+
+func (l queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, "gorm: "+msg, "args", args)
+}
+
+func (l queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level = slog.LevelError
+	case l.slowQuery > 0 && elapsed > l.slowQuery:
+		level = slog.LevelWarn
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	if level == slog.LevelError {
+		attrs = append(attrs, "error", err.Error())
+	}
+	slog.Log(ctx, level, "query", attrs...)
+}
+
+// ParamsFilter keeps the placeholders in logged SQL instead of the values
+func (l queryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
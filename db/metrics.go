@@ -0,0 +1,57 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sinscostank/bengkel-inventory/metrics"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// RegisterMetrics times every query conn runs and exports its connection pool
+// stats (open, in use and idle connections, waits) to m
+func RegisterMetrics(conn *gorm.DB, m *metrics.Metrics) error {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+	if err := m.Register(collectors.NewDBStatsCollector(sqlDB, conn.Dialector.Name())); err != nil {
+		return err
+	}
+
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(queryStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(queryStartKey)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			if table == "" {
+				table = "none"
+			}
+			m.ObserveQuery(operation, table, time.Since(start.(time.Time)))
+		}
+	}
+
+	cb := conn.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	)
+}
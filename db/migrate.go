@@ -1,26 +1,124 @@
 package db
 
 import (
-    "log"
+    "fmt"
+    "log/slog"
+    "sort"
+    "strings"
 
 	"github.com/sinscostank/bengkel-inventory/models"
     "gorm.io/gorm"
 )
 
-func RunMigration(db *gorm.DB) {
-    err := db.AutoMigrate(
-        &models.User{},
-        &models.Category{},
-        &models.Product{},
-        &models.Activity{},
-        &models.ActivityItem{},
-        &models.StockTransaction{},
-        &models.PriceHistory{},
-    )
+// Models lists every model stored in the database. The SQL migrations have to
+// create exactly these tables, which `migrate check` verifies.
+var Models = []interface{}{
+    &models.User{},
+    &models.Category{},
+    &models.Product{},
+    &models.Activity{},
+    &models.ActivityItem{},
+    &models.StockTransaction{},
+    &models.PriceHistory{},
+    &models.ReceiptTemplate{},
+    &models.InvoiceSequence{},
+    &models.Vehicle{},
+    &models.Supplier{},
+    &models.ProductFitment{},
+    &models.UserAuditLog{},
+    &models.UserSession{},
+    &models.RefreshToken{},
+    &models.Role{},
+    &models.RolePermission{},
+    &models.PasswordResetToken{},
+    &models.LoginAttempt{},
+    &models.LoginThrottle{},
+    &models.UserRecoveryCode{},
+    &models.LoginChallenge{},
+    &models.APIKey{},
+    &models.APIKeyScope{},
+    &models.AuditLog{},
+    &models.ScheduledPriceChange{},
+}
 
+// RunMigration applies the pending SQL migrations and seeds the built-in roles
+func RunMigration(db *gorm.DB) error {
+    migrator, err := NewMigrator(db)
     if err != nil {
-        log.Fatalf("AutoMigrate failed: %v", err)
+        return fmt.Errorf("loading migrations: %w", err)
     }
 
-    log.Println("✅ Database migrated successfully.")
-}
\ No newline at end of file
+    applied, err := migrator.Up()
+    if err != nil {
+        return fmt.Errorf("migrating: %w", err)
+    }
+
+    if err := SeedRoles(db); err != nil {
+        return fmt.Errorf("seeding roles: %w", err)
+    }
+
+    slog.Info("database migrated", "applied", applied)
+    return nil
+}
+
+// CheckSchema compares the database with the GORM models and returns every
+// difference: missing or unknown tables, columns and indexes.
+func CheckSchema(db *gorm.DB) ([]string, error) {
+    var problems []string
+    known := map[string]bool{SchemaVersion{}.TableName(): true}
+
+    for _, model := range Models {
+        stmt := &gorm.Statement{DB: db}
+        if err := stmt.Parse(model); err != nil {
+            return nil, err
+        }
+        table := stmt.Schema.Table
+        known[table] = true
+
+        if !db.Migrator().HasTable(model) {
+            problems = append(problems, fmt.Sprintf("table %s is missing", table))
+            continue
+        }
+
+        columnTypes, err := db.Migrator().ColumnTypes(model)
+        if err != nil {
+            return nil, err
+        }
+        columns := make(map[string]bool, len(columnTypes))
+        for _, c := range columnTypes {
+            columns[c.Name()] = true
+        }
+        for _, field := range stmt.Schema.Fields {
+            if field.DBName == "" {
+                continue
+            }
+            if !columns[field.DBName] {
+                problems = append(problems, fmt.Sprintf("column %s.%s is missing", table, field.DBName))
+            }
+            delete(columns, field.DBName)
+        }
+        for name := range columns {
+            problems = append(problems, fmt.Sprintf("column %s.%s is not in the models", table, name))
+        }
+
+        for _, idx := range stmt.Schema.ParseIndexes() {
+            if !db.Migrator().HasIndex(model, idx.Name) {
+                problems = append(problems, fmt.Sprintf("index %s on %s is missing", idx.Name, table))
+            }
+        }
+    }
+
+    tables, err := db.Migrator().GetTables()
+    if err != nil {
+        return nil, err
+    }
+    for _, table := range tables {
+        // sqlite_sequence and friends are SQLite's own bookkeeping
+        if !known[table] && !strings.HasPrefix(table, "sqlite_") {
+            problems = append(problems, fmt.Sprintf("table %s is not in the models", table))
+        }
+    }
+
+    sort.Strings(problems)
+    return problems, nil
+}
@@ -0,0 +1,184 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/db"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"gorm.io/gorm"
+)
+
+func TestMigrationsMatchModels(t *testing.T) {
+	conn := dbtest.Open(t)
+
+	problems, err := db.CheckSchema(conn)
+	if err != nil {
+		t.Fatalf("checking schema: %v", err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	conn := dbtest.Open(t)
+
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	applied, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("reading status: %v", err)
+	}
+	if _, err := migrator.Down(len(applied)); err != nil {
+		t.Fatalf("rolling back: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("migrating again: %v", err)
+	}
+
+	problems, err := db.CheckSchema(conn)
+	if err != nil {
+		t.Fatalf("checking schema: %v", err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
+
+// The models as AutoMigrate created them before versioned migrations. SQLite
+// has no enum type, so the MySQL enum columns are plain strings here.
+type (
+	legacyUser struct {
+		ID        uint   `gorm:"primaryKey;autoIncrement"`
+		Name      string `gorm:"size:255;not null"`
+		Email     string `gorm:"size:255;not null;unique"`
+		Password  string `gorm:"size:255;not null"`
+		Role      string `gorm:"size:50;not null"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	legacyCategory struct {
+		ID        uint   `gorm:"primaryKey;autoIncrement"`
+		Name      string `gorm:"size:255;not null;unique"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt  `gorm:"index"`
+		Products  []legacyProduct `gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	}
+	legacyProduct struct {
+		ID         uint    `gorm:"primaryKey;autoIncrement"`
+		Name       string  `gorm:"size:255;not null"`
+		Stock      int     `gorm:"not null;check:stock>=0"`
+		Price      float64 `gorm:"not null;check:price>=0"`
+		Location   string  `gorm:"size:255;not null"`
+		CategoryID uint    `gorm:"not null;index"`
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		DeletedAt  gorm.DeletedAt           `gorm:"index"`
+		Items      []legacyActivityItem     `gorm:"foreignKey:ProductID"`
+		StockTx    []legacyStockTransaction `gorm:"foreignKey:ProductID"`
+		PriceHist  []legacyPriceHistory     `gorm:"foreignKey:ProductID"`
+	}
+	legacyActivity struct {
+		ID        uint       `gorm:"primaryKey;autoIncrement"`
+		UserID    uint       `gorm:"not null;index"`
+		User      legacyUser `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+		Date      time.Time  `gorm:"not null"`
+		Status    string     `gorm:"size:20;not null"`
+		Type      string     `gorm:"size:20;not null"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt       `gorm:"index"`
+		Items     []legacyActivityItem `gorm:"foreignKey:ActivityID"`
+	}
+	legacyActivityItem struct {
+		ID             uint    `gorm:"primaryKey;autoIncrement"`
+		ActivityID     uint    `gorm:"not null;index"`
+		ProductID      uint    `gorm:"not null;index"`
+		Quantity       int     `gorm:"not null;check:quantity>0"`
+		PriceAtTime    float64 `gorm:"not null;check:price_at_time>=0"`
+		DiscountAmount float64 `gorm:"not null;default:0;check:discount_amount>=0"`
+		FinalPrice     float64 `gorm:"not null;check:final_price>=0"`
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
+		DeletedAt      gorm.DeletedAt           `gorm:"index"`
+		StockTx        []legacyStockTransaction `gorm:"foreignKey:ActivityItemID"`
+	}
+	legacyStockTransaction struct {
+		ID             uint      `gorm:"primaryKey;autoIncrement"`
+		ProductID      uint      `gorm:"not null;index"`
+		ActivityItemID *uint     `gorm:"index"`
+		ChangeQuantity int       `gorm:"not null;check:change_quantity<>0"`
+		Date           time.Time `gorm:"not null"`
+		Note           string    `gorm:"size:255"`
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
+		DeletedAt      gorm.DeletedAt `gorm:"index"`
+	}
+	legacyPriceHistory struct {
+		ID          uint      `gorm:"primaryKey;autoIncrement"`
+		ProductID   uint      `gorm:"not null;index"`
+		OldPrice    float64   `gorm:"not null;check:old_price>=0"`
+		NewPrice    float64   `gorm:"not null;check:new_price>=0"`
+		DateChanged time.Time `gorm:"not null"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		DeletedAt   gorm.DeletedAt `gorm:"index"`
+	}
+)
+
+func (legacyUser) TableName() string             { return "users" }
+func (legacyCategory) TableName() string         { return "categories" }
+func (legacyProduct) TableName() string          { return "products" }
+func (legacyActivity) TableName() string         { return "activities" }
+func (legacyActivityItem) TableName() string     { return "activity_items" }
+func (legacyStockTransaction) TableName() string { return "stock_transactions" }
+func (legacyPriceHistory) TableName() string     { return "price_histories" }
+
+func TestMigrationsUpgradeTheAutoMigrateBaseline(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Driver = "sqlite"
+	cfg.Path = filepath.Join(t.TempDir(), "legacy.db")
+	conn, err := db.Connect(cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := conn.AutoMigrate(&legacyUser{}, &legacyCategory{}, &legacyProduct{}, &legacyActivity{},
+		&legacyActivityItem{}, &legacyStockTransaction{}, &legacyPriceHistory{}); err != nil {
+		t.Fatalf("creating the baseline schema: %v", err)
+	}
+
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err == nil {
+		t.Fatal("migrated a database that was not baselined")
+	}
+	if err := migrator.Baseline(1); err != nil {
+		t.Fatalf("baselining: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	problems, err := db.CheckSchema(conn)
+	if err != nil {
+		t.Fatalf("checking schema: %v", err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
@@ -1,8 +0,0 @@
--- 0001_create_tables.down.sql
-DROP TABLE price_history;
-DROP TABLE stock_transactions;
-DROP TABLE activity_items;
-DROP TABLE activities;
-DROP TABLE products;
-DROP TABLE categories;
-DROP TABLE users;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- 0001_create_tables.down.sql
+DROP TABLE `price_histories`;
+DROP TABLE `stock_transactions`;
+DROP TABLE `activity_items`;
+DROP TABLE `activities`;
+DROP TABLE `products`;
+DROP TABLE `categories`;
+DROP TABLE `users`;
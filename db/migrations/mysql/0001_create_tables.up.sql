@@ -0,0 +1,119 @@
+-- 0001_create_tables.up.sql
+-- The schema AutoMigrate created before versioned migrations
+
+CREATE TABLE `users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `email` varchar(255) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `role` enum('admin','karyawan') NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_users_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+
+CREATE TABLE `categories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_categories_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_categories_name` UNIQUE (`name`)
+);
+
+CREATE TABLE `products` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `stock` bigint NOT NULL,
+  `price` double NOT NULL,
+  `location` varchar(255) NOT NULL,
+  `category_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_products_category_id` (`category_id`),
+  INDEX `idx_products_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_categories_products` FOREIGN KEY (`category_id`) REFERENCES `categories`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `chk_products_stock` CHECK (stock>=0),
+  CONSTRAINT `chk_products_price` CHECK (price>=0)
+);
+
+CREATE TABLE `activities` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `date` datetime(3) NOT NULL,
+  `status` enum('success','failed') NOT NULL,
+  `type` enum('inbound','outbound') NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_activities_user_id` (`user_id`),
+  INDEX `idx_activities_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_activities_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE TABLE `activity_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `activity_id` bigint unsigned NOT NULL,
+  `product_id` bigint unsigned NOT NULL,
+  `quantity` bigint NOT NULL,
+  `price_at_time` double NOT NULL,
+  `discount_amount` double NOT NULL DEFAULT 0,
+  `final_price` double NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_activity_items_activity_id` (`activity_id`),
+  INDEX `idx_activity_items_product_id` (`product_id`),
+  INDEX `idx_activity_items_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_products_items` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `fk_activities_items` FOREIGN KEY (`activity_id`) REFERENCES `activities`(`id`),
+  CONSTRAINT `chk_activity_items_quantity` CHECK (quantity>0),
+  CONSTRAINT `chk_activity_items_final_price` CHECK (final_price>=0),
+  CONSTRAINT `chk_activity_items_price_at_time` CHECK (price_at_time>=0),
+  CONSTRAINT `chk_activity_items_discount_amount` CHECK (discount_amount>=0)
+);
+
+CREATE TABLE `stock_transactions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `activity_item_id` bigint unsigned,
+  `change_quantity` bigint NOT NULL,
+  `date` datetime(3) NOT NULL,
+  `note` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_stock_transactions_product_id` (`product_id`),
+  INDEX `idx_stock_transactions_activity_item_id` (`activity_item_id`),
+  INDEX `idx_stock_transactions_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_activity_items_stock_tx` FOREIGN KEY (`activity_item_id`) REFERENCES `activity_items`(`id`),
+  CONSTRAINT `fk_products_stock_tx` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `chk_stock_transactions_change_quantity` CHECK (change_quantity<>0)
+);
+
+CREATE TABLE `price_histories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `old_price` double NOT NULL,
+  `new_price` double NOT NULL,
+  `date_changed` datetime(3) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_price_histories_product_id` (`product_id`),
+  INDEX `idx_price_histories_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_products_price_hist` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `chk_price_histories_old_price` CHECK (old_price>=0),
+  CONSTRAINT `chk_price_histories_new_price` CHECK (new_price>=0)
+);
@@ -0,0 +1,56 @@
+-- 0002_add_features.down.sql
+DROP TABLE `scheduled_price_changes`;
+DROP TABLE `audit_logs`;
+DROP TABLE `api_key_scopes`;
+DROP TABLE `api_keys`;
+DROP TABLE `login_challenges`;
+DROP TABLE `user_recovery_codes`;
+DROP TABLE `login_throttles`;
+DROP TABLE `login_attempts`;
+DROP TABLE `password_reset_tokens`;
+DROP TABLE `role_permissions`;
+DROP TABLE `roles`;
+DROP TABLE `refresh_tokens`;
+DROP TABLE `user_sessions`;
+DROP TABLE `user_audit_logs`;
+DROP TABLE `product_fitments`;
+DROP TABLE `vehicles`;
+DROP TABLE `invoice_sequences`;
+DROP TABLE `receipt_templates`;
+
+ALTER TABLE `price_histories`
+  DROP FOREIGN KEY `fk_price_histories_changed_by`,
+  DROP INDEX `idx_price_histories_changed_by_id`,
+  DROP COLUMN `changed_by_id`,
+  DROP COLUMN `source`;
+
+ALTER TABLE `activities`
+  DROP CHECK `chk_activities_tax_rate`,
+  DROP INDEX `idx_activities_branch_invoice`,
+  DROP COLUMN `branch`,
+  DROP COLUMN `invoice_number`,
+  DROP COLUMN `customer_name`,
+  DROP COLUMN `vehicle_plate`,
+  DROP COLUMN `tax_rate`,
+  DROP COLUMN `payment_status`;
+
+ALTER TABLE `products`
+  DROP INDEX `idx_products_sku`,
+  DROP INDEX `idx_products_brand`,
+  DROP COLUMN `sku`,
+  DROP COLUMN `brand`;
+
+ALTER TABLE `categories`
+  DROP FOREIGN KEY `fk_categories_children`,
+  DROP INDEX `idx_categories_parent_id`,
+  DROP COLUMN `parent_id`;
+
+-- Fails while users hold a role other than admin or karyawan
+ALTER TABLE `users`
+  DROP INDEX `idx_users_role`,
+  DROP COLUMN `is_active`,
+  DROP COLUMN `must_reset_password`,
+  DROP COLUMN `totp_enabled`,
+  DROP COLUMN `totp_secret`,
+  DROP COLUMN `totp_last_step`,
+  MODIFY `role` enum('admin','karyawan') NOT NULL;
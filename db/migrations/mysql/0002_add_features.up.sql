@@ -0,0 +1,293 @@
+-- 0002_add_features.up.sql
+-- Tables and columns added since the AutoMigrate baseline
+
+ALTER TABLE `users`
+  MODIFY `role` varchar(50) NOT NULL,
+  ADD `is_active` boolean NOT NULL DEFAULT true,
+  ADD `must_reset_password` boolean NOT NULL DEFAULT false,
+  ADD `totp_enabled` boolean NOT NULL DEFAULT false,
+  ADD `totp_secret` varchar(64),
+  ADD `totp_last_step` bigint NOT NULL DEFAULT 0,
+  ADD INDEX `idx_users_role` (`role`);
+
+ALTER TABLE `categories`
+  ADD `parent_id` bigint unsigned,
+  ADD INDEX `idx_categories_parent_id` (`parent_id`),
+  ADD CONSTRAINT `fk_categories_children` FOREIGN KEY (`parent_id`) REFERENCES `categories`(`id`);
+
+ALTER TABLE `products`
+  ADD `sku` varchar(64),
+  ADD `brand` varchar(100),
+  ADD UNIQUE INDEX `idx_products_sku` (`sku`),
+  ADD INDEX `idx_products_brand` (`brand`);
+
+ALTER TABLE `activities`
+  ADD `branch` varchar(50) NOT NULL DEFAULT 'default',
+  ADD `invoice_number` varchar(50),
+  ADD `customer_name` varchar(255),
+  ADD `vehicle_plate` varchar(20),
+  ADD `tax_rate` double NOT NULL DEFAULT 0,
+  ADD `payment_status` enum('paid','unpaid') NOT NULL DEFAULT 'paid',
+  ADD UNIQUE INDEX `idx_activities_branch_invoice` (`branch`,`invoice_number`),
+  ADD CONSTRAINT `chk_activities_tax_rate` CHECK (tax_rate>=0);
+
+ALTER TABLE `price_histories`
+  ADD `changed_by_id` bigint unsigned,
+  ADD `source` varchar(20) NOT NULL DEFAULT 'manual',
+  ADD INDEX `idx_price_histories_changed_by_id` (`changed_by_id`),
+  ADD CONSTRAINT `fk_price_histories_changed_by` FOREIGN KEY (`changed_by_id`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE TABLE `receipt_templates` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `branch` varchar(50) NOT NULL,
+  `workshop_name` varchar(255) NOT NULL,
+  `address` varchar(255),
+  `phone` varchar(50),
+  `footer` varchar(500),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_receipt_templates_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_receipt_templates_branch` UNIQUE (`branch`)
+);
+
+CREATE TABLE `invoice_sequences` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `branch` varchar(50) NOT NULL,
+  `year` bigint NOT NULL,
+  `last_number` bigint NOT NULL DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_invoice_sequences_branch_year` (`branch`,`year`)
+);
+
+CREATE TABLE `vehicles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `plate_number` varchar(20) NOT NULL,
+  `owner_name` varchar(255),
+  `make` varchar(100) NOT NULL,
+  `model` varchar(100) NOT NULL,
+  `year` bigint NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_vehicles_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_vehicles_plate_number` UNIQUE (`plate_number`)
+);
+
+CREATE TABLE `product_fitments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `make` varchar(100) NOT NULL,
+  `model` varchar(100) NOT NULL,
+  `year_from` bigint NOT NULL,
+  `year_to` bigint,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_product_fitments_product_id` (`product_id`),
+  INDEX `idx_fitments_vehicle` (`make`,`model`),
+  INDEX `idx_product_fitments_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_product_fitments_product` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE `user_audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `actor_id` bigint unsigned NOT NULL,
+  `target_user_id` bigint unsigned NOT NULL,
+  `action` varchar(50) NOT NULL,
+  `details` varchar(500),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_audit_logs_actor_id` (`actor_id`),
+  INDEX `idx_user_audit_logs_target_user_id` (`target_user_id`),
+  CONSTRAINT `fk_user_audit_logs_actor` FOREIGN KEY (`actor_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE TABLE `user_sessions` (
+  `id` varchar(32),
+  `user_id` bigint unsigned NOT NULL,
+  `user_agent` varchar(255),
+  `ip` varchar(45),
+  `revoked_at` datetime(3) NULL,
+  `last_used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_sessions_user_id` (`user_id`),
+  CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE `refresh_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `session_id` varchar(32) NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_refresh_tokens_session_id` (`session_id`),
+  CONSTRAINT `fk_refresh_tokens_session` FOREIGN KEY (`session_id`) REFERENCES `user_sessions`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_refresh_tokens_token_hash` UNIQUE (`token_hash`)
+);
+
+CREATE TABLE `roles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(50) NOT NULL,
+  `description` varchar(255),
+  `require_two_factor` boolean NOT NULL DEFAULT false,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_roles_name` UNIQUE (`name`)
+);
+
+CREATE TABLE `role_permissions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `role_id` bigint unsigned NOT NULL,
+  `permission` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_role_permissions_role_permission` (`role_id`,`permission`),
+  CONSTRAINT `fk_roles_permissions` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+);
+
+CREATE TABLE `password_reset_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_reset_tokens_user_id` (`user_id`),
+  CONSTRAINT `fk_password_reset_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_password_reset_tokens_token_hash` UNIQUE (`token_hash`)
+);
+
+CREATE TABLE `login_attempts` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `email` varchar(255) NOT NULL,
+  `ip` varchar(45),
+  `user_agent` varchar(255),
+  `success` boolean NOT NULL,
+  `reason` varchar(50),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_login_attempts_user_id` (`user_id`),
+  INDEX `idx_login_attempts_email` (`email`),
+  INDEX `idx_login_attempts_ip` (`ip`),
+  INDEX `idx_login_attempts_created_at` (`created_at`)
+);
+
+CREATE TABLE `login_throttles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `kind` varchar(10) NOT NULL,
+  `identifier` varchar(255) NOT NULL,
+  `failures` bigint NOT NULL DEFAULT 0,
+  `last_failed_at` datetime(3) NULL,
+  `locked_until` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_login_throttles_kind_identifier` (`kind`,`identifier`)
+);
+
+CREATE TABLE `user_recovery_codes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_recovery_codes_user_id` (`user_id`),
+  CONSTRAINT `fk_user_recovery_codes_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_user_recovery_codes_code_hash` UNIQUE (`code_hash`)
+);
+
+CREATE TABLE `login_challenges` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `attempts` bigint NOT NULL DEFAULT 0,
+  `expires_at` datetime(3) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_login_challenges_user_id` (`user_id`),
+  CONSTRAINT `fk_login_challenges_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_login_challenges_token_hash` UNIQUE (`token_hash`)
+);
+
+CREATE TABLE `api_keys` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL,
+  `prefix` varchar(16) NOT NULL,
+  `key_hash` varchar(64) NOT NULL,
+  `allowed_ips` varchar(500),
+  `expires_at` datetime(3) NULL,
+  `last_used_at` datetime(3) NULL,
+  `last_used_ip` varchar(45),
+  `revoked_at` datetime(3) NULL,
+  `created_by_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_api_keys_prefix` (`prefix`),
+  INDEX `idx_api_keys_created_by_id` (`created_by_id`),
+  CONSTRAINT `fk_api_keys_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `uni_api_keys_key_hash` UNIQUE (`key_hash`)
+);
+
+CREATE TABLE `api_key_scopes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `api_key_id` bigint unsigned NOT NULL,
+  `permission` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_api_key_scopes_key_permission` (`api_key_id`,`permission`),
+  CONSTRAINT `fk_api_keys_scopes` FOREIGN KEY (`api_key_id`) REFERENCES `api_keys`(`id`)
+);
+
+CREATE TABLE `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `actor_id` bigint unsigned,
+  `api_key_id` bigint unsigned,
+  `entity` varchar(50) NOT NULL,
+  `entity_id` varchar(100),
+  `action` varchar(50) NOT NULL,
+  `method` varchar(10) NOT NULL,
+  `path` varchar(255) NOT NULL,
+  `status` bigint,
+  `before` text,
+  `after` text,
+  `changes` text,
+  `ip` varchar(45),
+  `request_id` varchar(64),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_logs_actor_id` (`actor_id`),
+  INDEX `idx_audit_logs_api_key_id` (`api_key_id`),
+  INDEX `idx_audit_logs_entity` (`entity`,`entity_id`),
+  INDEX `idx_audit_logs_request_id` (`request_id`),
+  INDEX `idx_audit_logs_created_at` (`created_at`),
+  CONSTRAINT `fk_audit_logs_actor` FOREIGN KEY (`actor_id`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+CREATE TABLE `scheduled_price_changes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `new_price` double NOT NULL,
+  `effective_at` datetime(3) NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `note` varchar(255),
+  `created_by_id` bigint unsigned NOT NULL,
+  `applied_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_scheduled_price_changes_product_id` (`product_id`),
+  INDEX `idx_scheduled_price_changes_due` (`effective_at`,`status`),
+  CONSTRAINT `fk_scheduled_price_changes_product` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `fk_scheduled_price_changes_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `chk_scheduled_price_changes_new_price` CHECK (new_price>=0)
+);
@@ -0,0 +1,11 @@
+-- 0003_activity_status_checks.down.sql
+
+ALTER TABLE `activities`
+  DROP CHECK `chk_activities_payment_status`,
+  DROP CHECK `chk_activities_status`,
+  DROP CHECK `chk_activities_type`;
+
+ALTER TABLE `activities`
+  MODIFY `payment_status` enum('paid','unpaid') NOT NULL DEFAULT 'paid',
+  MODIFY `status` enum('success','failed') NOT NULL,
+  MODIFY `type` enum('inbound','outbound') NOT NULL;
@@ -0,0 +1,12 @@
+-- 0003_activity_status_checks.up.sql
+-- Replace the MySQL-only enum columns with varchar and check constraints
+
+ALTER TABLE `activities`
+  MODIFY `payment_status` varchar(20) NOT NULL DEFAULT 'paid',
+  MODIFY `status` varchar(20) NOT NULL,
+  MODIFY `type` varchar(20) NOT NULL;
+
+ALTER TABLE `activities`
+  ADD CONSTRAINT `chk_activities_payment_status` CHECK (payment_status IN ('paid','unpaid')),
+  ADD CONSTRAINT `chk_activities_status` CHECK (status IN ('success','failed')),
+  ADD CONSTRAINT `chk_activities_type` CHECK (type IN ('inbound','outbound'));
@@ -0,0 +1,8 @@
+-- 0004_create_suppliers.down.sql
+
+ALTER TABLE `products`
+  DROP FOREIGN KEY `fk_products_supplier`,
+  DROP INDEX `idx_products_supplier_id`,
+  DROP COLUMN `supplier_id`;
+
+DROP TABLE `suppliers`;
@@ -0,0 +1,20 @@
+-- 0004_create_suppliers.up.sql
+-- Suppliers, and the supplier each product is bought from
+
+CREATE TABLE `suppliers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `phone` varchar(50),
+  `address` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_suppliers_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_suppliers_name` UNIQUE (`name`)
+);
+
+ALTER TABLE `products`
+  ADD `supplier_id` bigint unsigned NULL,
+  ADD INDEX `idx_products_supplier_id` (`supplier_id`),
+  ADD CONSTRAINT `fk_products_supplier` FOREIGN KEY (`supplier_id`) REFERENCES `suppliers`(`id`) ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,121 @@
+-- 0001_create_tables.up.sql
+-- The schema AutoMigrate created before versioned migrations
+
+CREATE TABLE "users" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "email" varchar(255) NOT NULL,
+  "password" varchar(255) NOT NULL,
+  "role" varchar(50) NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+CREATE INDEX "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE "categories" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_categories_name" UNIQUE ("name")
+);
+CREATE INDEX "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE "products" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "stock" bigint NOT NULL,
+  "price" decimal NOT NULL,
+  "location" varchar(255) NOT NULL,
+  "category_id" bigint NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_categories_products" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT "chk_products_price" CHECK (price>=0),
+  CONSTRAINT "chk_products_stock" CHECK (stock>=0)
+);
+CREATE INDEX "idx_products_deleted_at" ON "products" ("deleted_at");
+CREATE INDEX "idx_products_category_id" ON "products" ("category_id");
+
+CREATE TABLE "activities" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "date" timestamptz NOT NULL,
+  "status" varchar(20) NOT NULL,
+  "type" varchar(20) NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_activities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT "chk_activities_status" CHECK (status IN ('success','failed')),
+  CONSTRAINT "chk_activities_type" CHECK (type IN ('inbound','outbound'))
+);
+CREATE INDEX "idx_activities_deleted_at" ON "activities" ("deleted_at");
+CREATE INDEX "idx_activities_user_id" ON "activities" ("user_id");
+
+CREATE TABLE "activity_items" (
+  "id" bigserial,
+  "activity_id" bigint NOT NULL,
+  "product_id" bigint NOT NULL,
+  "quantity" bigint NOT NULL,
+  "price_at_time" decimal NOT NULL,
+  "discount_amount" decimal NOT NULL DEFAULT 0,
+  "final_price" decimal NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_activities_items" FOREIGN KEY ("activity_id") REFERENCES "activities"("id"),
+  CONSTRAINT "fk_products_items" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+  CONSTRAINT "chk_activity_items_price_at_time" CHECK (price_at_time>=0),
+  CONSTRAINT "chk_activity_items_discount_amount" CHECK (discount_amount>=0),
+  CONSTRAINT "chk_activity_items_quantity" CHECK (quantity>0),
+  CONSTRAINT "chk_activity_items_final_price" CHECK (final_price>=0)
+);
+CREATE INDEX "idx_activity_items_deleted_at" ON "activity_items" ("deleted_at");
+CREATE INDEX "idx_activity_items_product_id" ON "activity_items" ("product_id");
+CREATE INDEX "idx_activity_items_activity_id" ON "activity_items" ("activity_id");
+
+CREATE TABLE "stock_transactions" (
+  "id" bigserial,
+  "product_id" bigint NOT NULL,
+  "activity_item_id" bigint,
+  "change_quantity" bigint NOT NULL,
+  "date" timestamptz NOT NULL,
+  "note" varchar(255),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_activity_items_stock_tx" FOREIGN KEY ("activity_item_id") REFERENCES "activity_items"("id"),
+  CONSTRAINT "fk_products_stock_tx" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+  CONSTRAINT "chk_stock_transactions_change_quantity" CHECK (change_quantity<>0)
+);
+CREATE INDEX "idx_stock_transactions_deleted_at" ON "stock_transactions" ("deleted_at");
+CREATE INDEX "idx_stock_transactions_activity_item_id" ON "stock_transactions" ("activity_item_id");
+CREATE INDEX "idx_stock_transactions_product_id" ON "stock_transactions" ("product_id");
+
+CREATE TABLE "price_histories" (
+  "id" bigserial,
+  "product_id" bigint NOT NULL,
+  "old_price" decimal NOT NULL,
+  "new_price" decimal NOT NULL,
+  "date_changed" timestamptz NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_products_price_hist" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+  CONSTRAINT "chk_price_histories_old_price" CHECK (old_price>=0),
+  CONSTRAINT "chk_price_histories_new_price" CHECK (new_price>=0)
+);
+CREATE INDEX "idx_price_histories_deleted_at" ON "price_histories" ("deleted_at");
+CREATE INDEX "idx_price_histories_product_id" ON "price_histories" ("product_id");
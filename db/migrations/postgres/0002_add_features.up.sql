@@ -0,0 +1,293 @@
+-- 0002_add_features.up.sql
+-- Tables and columns added since the AutoMigrate baseline
+
+ALTER TABLE "users"
+  ADD COLUMN "is_active" boolean NOT NULL DEFAULT true,
+  ADD COLUMN "must_reset_password" boolean NOT NULL DEFAULT false,
+  ADD COLUMN "totp_enabled" boolean NOT NULL DEFAULT false,
+  ADD COLUMN "totp_secret" varchar(64),
+  ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+CREATE INDEX "idx_users_role" ON "users" ("role");
+
+ALTER TABLE "categories"
+  ADD COLUMN "parent_id" bigint,
+  ADD CONSTRAINT "fk_categories_children" FOREIGN KEY ("parent_id") REFERENCES "categories"("id");
+CREATE INDEX "idx_categories_parent_id" ON "categories" ("parent_id");
+
+ALTER TABLE "products"
+  ADD COLUMN "sku" varchar(64),
+  ADD COLUMN "brand" varchar(100);
+CREATE INDEX "idx_products_brand" ON "products" ("brand");
+CREATE UNIQUE INDEX "idx_products_sku" ON "products" ("sku");
+
+ALTER TABLE "activities"
+  ADD COLUMN "branch" varchar(50) NOT NULL DEFAULT 'default',
+  ADD COLUMN "invoice_number" varchar(50),
+  ADD COLUMN "customer_name" varchar(255),
+  ADD COLUMN "vehicle_plate" varchar(20),
+  ADD COLUMN "tax_rate" decimal NOT NULL DEFAULT 0,
+  ADD COLUMN "payment_status" varchar(20) NOT NULL DEFAULT 'paid',
+  ADD CONSTRAINT "chk_activities_tax_rate" CHECK (tax_rate>=0),
+  ADD CONSTRAINT "chk_activities_payment_status" CHECK (payment_status IN ('paid','unpaid'));
+CREATE UNIQUE INDEX "idx_activities_branch_invoice" ON "activities" ("branch","invoice_number");
+
+ALTER TABLE "price_histories"
+  ADD COLUMN "changed_by_id" bigint,
+  ADD COLUMN "source" varchar(20) NOT NULL DEFAULT 'manual',
+  ADD CONSTRAINT "fk_price_histories_changed_by" FOREIGN KEY ("changed_by_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+CREATE INDEX "idx_price_histories_changed_by_id" ON "price_histories" ("changed_by_id");
+
+CREATE TABLE "receipt_templates" (
+  "id" bigserial,
+  "branch" varchar(50) NOT NULL,
+  "workshop_name" varchar(255) NOT NULL,
+  "address" varchar(255),
+  "phone" varchar(50),
+  "footer" varchar(500),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_receipt_templates_branch" UNIQUE ("branch")
+);
+CREATE INDEX "idx_receipt_templates_deleted_at" ON "receipt_templates" ("deleted_at");
+
+CREATE TABLE "invoice_sequences" (
+  "id" bigserial,
+  "branch" varchar(50) NOT NULL,
+  "year" bigint NOT NULL,
+  "last_number" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_invoice_sequences_branch_year" ON "invoice_sequences" ("branch","year");
+
+CREATE TABLE "vehicles" (
+  "id" bigserial,
+  "plate_number" varchar(20) NOT NULL,
+  "owner_name" varchar(255),
+  "make" varchar(100) NOT NULL,
+  "model" varchar(100) NOT NULL,
+  "year" bigint NOT NULL,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_vehicles_plate_number" UNIQUE ("plate_number")
+);
+CREATE INDEX "idx_vehicles_deleted_at" ON "vehicles" ("deleted_at");
+
+CREATE TABLE "product_fitments" (
+  "id" bigserial,
+  "product_id" bigint NOT NULL,
+  "make" varchar(100) NOT NULL,
+  "model" varchar(100) NOT NULL,
+  "year_from" bigint NOT NULL,
+  "year_to" bigint,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_product_fitments_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "idx_product_fitments_deleted_at" ON "product_fitments" ("deleted_at");
+CREATE INDEX "idx_fitments_vehicle" ON "product_fitments" ("make","model");
+CREATE INDEX "idx_product_fitments_product_id" ON "product_fitments" ("product_id");
+
+CREATE TABLE "user_audit_logs" (
+  "id" bigserial,
+  "actor_id" bigint NOT NULL,
+  "target_user_id" bigint NOT NULL,
+  "action" varchar(50) NOT NULL,
+  "details" varchar(500),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_audit_logs_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE INDEX "idx_user_audit_logs_target_user_id" ON "user_audit_logs" ("target_user_id");
+CREATE INDEX "idx_user_audit_logs_actor_id" ON "user_audit_logs" ("actor_id");
+
+CREATE TABLE "user_sessions" (
+  "id" varchar(32),
+  "user_id" bigint NOT NULL,
+  "user_agent" varchar(255),
+  "ip" varchar(45),
+  "revoked_at" timestamptz,
+  "last_used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
+
+CREATE TABLE "refresh_tokens" (
+  "id" bigserial,
+  "session_id" varchar(32) NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_refresh_tokens_session" FOREIGN KEY ("session_id") REFERENCES "user_sessions"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT "uni_refresh_tokens_token_hash" UNIQUE ("token_hash")
+);
+CREATE INDEX "idx_refresh_tokens_session_id" ON "refresh_tokens" ("session_id");
+
+CREATE TABLE "roles" (
+  "id" bigserial,
+  "name" varchar(50) NOT NULL,
+  "description" varchar(255),
+  "require_two_factor" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_roles_name" UNIQUE ("name")
+);
+
+CREATE TABLE "role_permissions" (
+  "id" bigserial,
+  "role_id" bigint NOT NULL,
+  "permission" varchar(50) NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_roles_permissions" FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
+CREATE UNIQUE INDEX "idx_role_permissions_role_permission" ON "role_permissions" ("role_id","permission");
+
+CREATE TABLE "password_reset_tokens" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_password_reset_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT "uni_password_reset_tokens_token_hash" UNIQUE ("token_hash")
+);
+CREATE INDEX "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+
+CREATE TABLE "login_attempts" (
+  "id" bigserial,
+  "user_id" bigint,
+  "email" varchar(255) NOT NULL,
+  "ip" varchar(45),
+  "user_agent" varchar(255),
+  "success" boolean NOT NULL,
+  "reason" varchar(50),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_login_attempts_created_at" ON "login_attempts" ("created_at");
+CREATE INDEX "idx_login_attempts_ip" ON "login_attempts" ("ip");
+CREATE INDEX "idx_login_attempts_email" ON "login_attempts" ("email");
+CREATE INDEX "idx_login_attempts_user_id" ON "login_attempts" ("user_id");
+
+CREATE TABLE "login_throttles" (
+  "id" bigserial,
+  "kind" varchar(10) NOT NULL,
+  "identifier" varchar(255) NOT NULL,
+  "failures" bigint NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz,
+  "locked_until" timestamptz,
+  PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_login_throttles_kind_identifier" ON "login_throttles" ("kind","identifier");
+
+CREATE TABLE "user_recovery_codes" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "code_hash" varchar(64) NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT "uni_user_recovery_codes_code_hash" UNIQUE ("code_hash")
+);
+CREATE INDEX "idx_user_recovery_codes_user_id" ON "user_recovery_codes" ("user_id");
+
+CREATE TABLE "login_challenges" (
+  "id" bigserial,
+  "user_id" bigint NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_login_challenges_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT "uni_login_challenges_token_hash" UNIQUE ("token_hash")
+);
+CREATE INDEX "idx_login_challenges_user_id" ON "login_challenges" ("user_id");
+
+CREATE TABLE "api_keys" (
+  "id" bigserial,
+  "name" varchar(100) NOT NULL,
+  "prefix" varchar(16) NOT NULL,
+  "key_hash" varchar(64) NOT NULL,
+  "allowed_ips" varchar(500),
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "last_used_ip" varchar(45),
+  "revoked_at" timestamptz,
+  "created_by_id" bigint NOT NULL,
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_api_keys_created_by" FOREIGN KEY ("created_by_id") REFERENCES "users"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT "uni_api_keys_key_hash" UNIQUE ("key_hash")
+);
+CREATE INDEX "idx_api_keys_created_by_id" ON "api_keys" ("created_by_id");
+CREATE INDEX "idx_api_keys_prefix" ON "api_keys" ("prefix");
+
+CREATE TABLE "api_key_scopes" (
+  "id" bigserial,
+  "api_key_id" bigint NOT NULL,
+  "permission" varchar(50) NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_api_keys_scopes" FOREIGN KEY ("api_key_id") REFERENCES "api_keys"("id")
+);
+CREATE UNIQUE INDEX "idx_api_key_scopes_key_permission" ON "api_key_scopes" ("api_key_id","permission");
+
+CREATE TABLE "audit_logs" (
+  "id" bigserial,
+  "actor_id" bigint,
+  "api_key_id" bigint,
+  "entity" varchar(50) NOT NULL,
+  "entity_id" varchar(100),
+  "action" varchar(50) NOT NULL,
+  "method" varchar(10) NOT NULL,
+  "path" varchar(255) NOT NULL,
+  "status" bigint,
+  "before" text,
+  "after" text,
+  "changes" text,
+  "ip" varchar(45),
+  "request_id" varchar(64),
+  "created_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_audit_logs_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+CREATE INDEX "idx_audit_logs_entity" ON "audit_logs" ("entity","entity_id");
+CREATE INDEX "idx_audit_logs_api_key_id" ON "audit_logs" ("api_key_id");
+CREATE INDEX "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+
+CREATE TABLE "scheduled_price_changes" (
+  "id" bigserial,
+  "product_id" bigint NOT NULL,
+  "new_price" decimal NOT NULL,
+  "effective_at" timestamptz NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'pending',
+  "note" varchar(255),
+  "created_by_id" bigint NOT NULL,
+  "applied_at" timestamptz,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_scheduled_price_changes_created_by" FOREIGN KEY ("created_by_id") REFERENCES "users"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT "fk_scheduled_price_changes_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT "chk_scheduled_price_changes_new_price" CHECK (new_price>=0)
+);
+CREATE INDEX "idx_scheduled_price_changes_due" ON "scheduled_price_changes" ("effective_at","status");
+CREATE INDEX "idx_scheduled_price_changes_product_id" ON "scheduled_price_changes" ("product_id");
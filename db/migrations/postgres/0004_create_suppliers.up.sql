@@ -0,0 +1,20 @@
+-- 0004_create_suppliers.up.sql
+-- Suppliers, and the supplier each product is bought from
+
+CREATE TABLE "suppliers" (
+  "id" bigserial,
+  "name" varchar(255) NOT NULL,
+  "phone" varchar(50),
+  "address" varchar(255),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uni_suppliers_name" UNIQUE ("name")
+);
+CREATE INDEX "idx_suppliers_deleted_at" ON "suppliers" ("deleted_at");
+
+ALTER TABLE "products"
+  ADD COLUMN "supplier_id" bigint,
+  ADD CONSTRAINT "fk_products_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+CREATE INDEX "idx_products_supplier_id" ON "products" ("supplier_id");
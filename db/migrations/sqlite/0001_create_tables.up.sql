@@ -0,0 +1,114 @@
+-- 0001_create_tables.up.sql
+-- The schema AutoMigrate created before versioned migrations
+
+CREATE TABLE `users` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `email` text NOT NULL,
+  `password` text NOT NULL,
+  `role` text NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+CREATE INDEX `idx_users_deleted_at` ON `users`(`deleted_at`);
+
+CREATE TABLE `categories` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `uni_categories_name` UNIQUE (`name`)
+);
+CREATE INDEX `idx_categories_deleted_at` ON `categories`(`deleted_at`);
+
+CREATE TABLE `products` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `stock` integer NOT NULL,
+  `price` real NOT NULL,
+  `location` text NOT NULL,
+  `category_id` integer NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_categories_products` FOREIGN KEY (`category_id`) REFERENCES `categories`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `chk_products_stock` CHECK (stock>=0),
+  CONSTRAINT `chk_products_price` CHECK (price>=0)
+);
+CREATE INDEX `idx_products_deleted_at` ON `products`(`deleted_at`);
+CREATE INDEX `idx_products_category_id` ON `products`(`category_id`);
+
+CREATE TABLE `activities` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `date` datetime NOT NULL,
+  `status` text NOT NULL,
+  `type` text NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_activities_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `chk_activities_status` CHECK (status IN ('success','failed')),
+  CONSTRAINT `chk_activities_type` CHECK (type IN ('inbound','outbound'))
+);
+CREATE INDEX `idx_activities_deleted_at` ON `activities`(`deleted_at`);
+CREATE INDEX `idx_activities_user_id` ON `activities`(`user_id`);
+
+CREATE TABLE `activity_items` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `activity_id` integer NOT NULL,
+  `product_id` integer NOT NULL,
+  `quantity` integer NOT NULL,
+  `price_at_time` real NOT NULL,
+  `discount_amount` real NOT NULL DEFAULT 0,
+  `final_price` real NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_products_items` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `fk_activities_items` FOREIGN KEY (`activity_id`) REFERENCES `activities`(`id`),
+  CONSTRAINT `chk_activity_items_quantity` CHECK (quantity>0),
+  CONSTRAINT `chk_activity_items_price_at_time` CHECK (price_at_time>=0),
+  CONSTRAINT `chk_activity_items_discount_amount` CHECK (discount_amount>=0),
+  CONSTRAINT `chk_activity_items_final_price` CHECK (final_price>=0)
+);
+CREATE INDEX `idx_activity_items_deleted_at` ON `activity_items`(`deleted_at`);
+CREATE INDEX `idx_activity_items_product_id` ON `activity_items`(`product_id`);
+CREATE INDEX `idx_activity_items_activity_id` ON `activity_items`(`activity_id`);
+
+CREATE TABLE `stock_transactions` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_id` integer NOT NULL,
+  `activity_item_id` integer,
+  `change_quantity` integer NOT NULL,
+  `date` datetime NOT NULL,
+  `note` text,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_activity_items_stock_tx` FOREIGN KEY (`activity_item_id`) REFERENCES `activity_items`(`id`),
+  CONSTRAINT `fk_products_stock_tx` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `chk_stock_transactions_change_quantity` CHECK (change_quantity<>0)
+);
+CREATE INDEX `idx_stock_transactions_deleted_at` ON `stock_transactions`(`deleted_at`);
+CREATE INDEX `idx_stock_transactions_activity_item_id` ON `stock_transactions`(`activity_item_id`);
+CREATE INDEX `idx_stock_transactions_product_id` ON `stock_transactions`(`product_id`);
+
+CREATE TABLE `price_histories` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_id` integer NOT NULL,
+  `old_price` real NOT NULL,
+  `new_price` real NOT NULL,
+  `date_changed` datetime NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_products_price_hist` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`),
+  CONSTRAINT `chk_price_histories_old_price` CHECK (old_price>=0),
+  CONSTRAINT `chk_price_histories_new_price` CHECK (new_price>=0)
+);
+CREATE INDEX `idx_price_histories_deleted_at` ON `price_histories`(`deleted_at`);
+CREATE INDEX `idx_price_histories_product_id` ON `price_histories`(`product_id`);
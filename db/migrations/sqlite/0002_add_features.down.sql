@@ -0,0 +1,46 @@
+-- 0002_add_features.down.sql
+DROP TABLE `scheduled_price_changes`;
+DROP TABLE `audit_logs`;
+DROP TABLE `api_key_scopes`;
+DROP TABLE `api_keys`;
+DROP TABLE `login_challenges`;
+DROP TABLE `user_recovery_codes`;
+DROP TABLE `login_throttles`;
+DROP TABLE `login_attempts`;
+DROP TABLE `password_reset_tokens`;
+DROP TABLE `role_permissions`;
+DROP TABLE `roles`;
+DROP TABLE `refresh_tokens`;
+DROP TABLE `user_sessions`;
+DROP TABLE `user_audit_logs`;
+DROP TABLE `product_fitments`;
+DROP TABLE `vehicles`;
+DROP TABLE `invoice_sequences`;
+DROP TABLE `receipt_templates`;
+
+DROP INDEX `idx_price_histories_changed_by_id`;
+ALTER TABLE `price_histories` DROP COLUMN `changed_by_id`;
+ALTER TABLE `price_histories` DROP COLUMN `source`;
+
+DROP INDEX `idx_activities_branch_invoice`;
+ALTER TABLE `activities` DROP COLUMN `branch`;
+ALTER TABLE `activities` DROP COLUMN `invoice_number`;
+ALTER TABLE `activities` DROP COLUMN `customer_name`;
+ALTER TABLE `activities` DROP COLUMN `vehicle_plate`;
+ALTER TABLE `activities` DROP COLUMN `tax_rate`;
+ALTER TABLE `activities` DROP COLUMN `payment_status`;
+
+DROP INDEX `idx_products_sku`;
+DROP INDEX `idx_products_brand`;
+ALTER TABLE `products` DROP COLUMN `sku`;
+ALTER TABLE `products` DROP COLUMN `brand`;
+
+DROP INDEX `idx_categories_parent_id`;
+ALTER TABLE `categories` DROP COLUMN `parent_id`;
+
+DROP INDEX `idx_users_role`;
+ALTER TABLE `users` DROP COLUMN `is_active`;
+ALTER TABLE `users` DROP COLUMN `must_reset_password`;
+ALTER TABLE `users` DROP COLUMN `totp_enabled`;
+ALTER TABLE `users` DROP COLUMN `totp_secret`;
+ALTER TABLE `users` DROP COLUMN `totp_last_step`;
@@ -0,0 +1,268 @@
+-- 0002_add_features.up.sql
+-- Tables and columns added since the AutoMigrate baseline
+
+ALTER TABLE `users` ADD COLUMN `is_active` numeric NOT NULL DEFAULT true;
+ALTER TABLE `users` ADD COLUMN `must_reset_password` numeric NOT NULL DEFAULT false;
+ALTER TABLE `users` ADD COLUMN `totp_enabled` numeric NOT NULL DEFAULT false;
+ALTER TABLE `users` ADD COLUMN `totp_secret` text;
+ALTER TABLE `users` ADD COLUMN `totp_last_step` integer NOT NULL DEFAULT 0;
+CREATE INDEX `idx_users_role` ON `users`(`role`);
+
+-- SQLite can only add a foreign key together with the column
+ALTER TABLE `categories` ADD COLUMN `parent_id` integer REFERENCES `categories`(`id`);
+CREATE INDEX `idx_categories_parent_id` ON `categories`(`parent_id`);
+
+ALTER TABLE `products` ADD COLUMN `sku` text;
+ALTER TABLE `products` ADD COLUMN `brand` text;
+CREATE INDEX `idx_products_brand` ON `products`(`brand`);
+CREATE UNIQUE INDEX `idx_products_sku` ON `products`(`sku`);
+
+ALTER TABLE `activities` ADD COLUMN `branch` text NOT NULL DEFAULT 'default';
+ALTER TABLE `activities` ADD COLUMN `invoice_number` text;
+ALTER TABLE `activities` ADD COLUMN `customer_name` text;
+ALTER TABLE `activities` ADD COLUMN `vehicle_plate` text;
+ALTER TABLE `activities` ADD COLUMN `tax_rate` real NOT NULL DEFAULT 0 CONSTRAINT `chk_activities_tax_rate` CHECK (tax_rate>=0);
+ALTER TABLE `activities` ADD COLUMN `payment_status` text NOT NULL DEFAULT 'paid' CONSTRAINT `chk_activities_payment_status` CHECK (payment_status IN ('paid','unpaid'));
+CREATE UNIQUE INDEX `idx_activities_branch_invoice` ON `activities`(`branch`,`invoice_number`);
+
+ALTER TABLE `price_histories` ADD COLUMN `changed_by_id` integer REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+ALTER TABLE `price_histories` ADD COLUMN `source` text NOT NULL DEFAULT 'manual';
+CREATE INDEX `idx_price_histories_changed_by_id` ON `price_histories`(`changed_by_id`);
+
+CREATE TABLE `receipt_templates` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `branch` text NOT NULL,
+  `workshop_name` text NOT NULL,
+  `address` text,
+  `phone` text,
+  `footer` text,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `uni_receipt_templates_branch` UNIQUE (`branch`)
+);
+CREATE INDEX `idx_receipt_templates_deleted_at` ON `receipt_templates`(`deleted_at`);
+
+CREATE TABLE `invoice_sequences` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `branch` text NOT NULL,
+  `year` integer NOT NULL,
+  `last_number` integer NOT NULL DEFAULT 0,
+  `created_at` datetime,
+  `updated_at` datetime
+);
+CREATE UNIQUE INDEX `idx_invoice_sequences_branch_year` ON `invoice_sequences`(`branch`,`year`);
+
+CREATE TABLE `vehicles` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `plate_number` text NOT NULL,
+  `owner_name` text,
+  `make` text NOT NULL,
+  `model` text NOT NULL,
+  `year` integer NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `uni_vehicles_plate_number` UNIQUE (`plate_number`)
+);
+CREATE INDEX `idx_vehicles_deleted_at` ON `vehicles`(`deleted_at`);
+
+CREATE TABLE `product_fitments` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_id` integer NOT NULL,
+  `make` text NOT NULL,
+  `model` text NOT NULL,
+  `year_from` integer NOT NULL,
+  `year_to` integer,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_product_fitments_product` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX `idx_product_fitments_deleted_at` ON `product_fitments`(`deleted_at`);
+CREATE INDEX `idx_fitments_vehicle` ON `product_fitments`(`make`,`model`);
+CREATE INDEX `idx_product_fitments_product_id` ON `product_fitments`(`product_id`);
+
+CREATE TABLE `user_audit_logs` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `actor_id` integer NOT NULL,
+  `target_user_id` integer NOT NULL,
+  `action` text NOT NULL,
+  `details` text,
+  `created_at` datetime,
+  CONSTRAINT `fk_user_audit_logs_actor` FOREIGN KEY (`actor_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE INDEX `idx_user_audit_logs_target_user_id` ON `user_audit_logs`(`target_user_id`);
+CREATE INDEX `idx_user_audit_logs_actor_id` ON `user_audit_logs`(`actor_id`);
+
+CREATE TABLE `user_sessions` (
+  `id` text,
+  `user_id` integer NOT NULL,
+  `user_agent` text,
+  `ip` text,
+  `revoked_at` datetime,
+  `last_used_at` datetime,
+  `created_at` datetime,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX `idx_user_sessions_user_id` ON `user_sessions`(`user_id`);
+
+CREATE TABLE `refresh_tokens` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `session_id` text NOT NULL,
+  `token_hash` text NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime,
+  `created_at` datetime,
+  CONSTRAINT `fk_refresh_tokens_session` FOREIGN KEY (`session_id`) REFERENCES `user_sessions`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_refresh_tokens_token_hash` UNIQUE (`token_hash`)
+);
+CREATE INDEX `idx_refresh_tokens_session_id` ON `refresh_tokens`(`session_id`);
+
+CREATE TABLE `roles` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `description` text,
+  `require_two_factor` numeric NOT NULL DEFAULT false,
+  `created_at` datetime,
+  `updated_at` datetime,
+  CONSTRAINT `uni_roles_name` UNIQUE (`name`)
+);
+
+CREATE TABLE `role_permissions` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `role_id` integer NOT NULL,
+  `permission` text NOT NULL,
+  CONSTRAINT `fk_roles_permissions` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+);
+CREATE UNIQUE INDEX `idx_role_permissions_role_permission` ON `role_permissions`(`role_id`,`permission`);
+
+CREATE TABLE `password_reset_tokens` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `token_hash` text NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime,
+  `created_at` datetime,
+  CONSTRAINT `fk_password_reset_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_password_reset_tokens_token_hash` UNIQUE (`token_hash`)
+);
+CREATE INDEX `idx_password_reset_tokens_user_id` ON `password_reset_tokens`(`user_id`);
+
+CREATE TABLE `login_attempts` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer,
+  `email` text NOT NULL,
+  `ip` text,
+  `user_agent` text,
+  `success` numeric NOT NULL,
+  `reason` text,
+  `created_at` datetime
+);
+CREATE INDEX `idx_login_attempts_created_at` ON `login_attempts`(`created_at`);
+CREATE INDEX `idx_login_attempts_ip` ON `login_attempts`(`ip`);
+CREATE INDEX `idx_login_attempts_email` ON `login_attempts`(`email`);
+CREATE INDEX `idx_login_attempts_user_id` ON `login_attempts`(`user_id`);
+
+CREATE TABLE `login_throttles` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `kind` text NOT NULL,
+  `identifier` text NOT NULL,
+  `failures` integer NOT NULL DEFAULT 0,
+  `last_failed_at` datetime,
+  `locked_until` datetime
+);
+CREATE UNIQUE INDEX `idx_login_throttles_kind_identifier` ON `login_throttles`(`kind`,`identifier`);
+
+CREATE TABLE `user_recovery_codes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `code_hash` text NOT NULL,
+  `used_at` datetime,
+  `created_at` datetime,
+  CONSTRAINT `fk_user_recovery_codes_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_user_recovery_codes_code_hash` UNIQUE (`code_hash`)
+);
+CREATE INDEX `idx_user_recovery_codes_user_id` ON `user_recovery_codes`(`user_id`);
+
+CREATE TABLE `login_challenges` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `token_hash` text NOT NULL,
+  `attempts` integer NOT NULL DEFAULT 0,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime,
+  `created_at` datetime,
+  CONSTRAINT `fk_login_challenges_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `uni_login_challenges_token_hash` UNIQUE (`token_hash`)
+);
+CREATE INDEX `idx_login_challenges_user_id` ON `login_challenges`(`user_id`);
+
+CREATE TABLE `api_keys` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `prefix` text NOT NULL,
+  `key_hash` text NOT NULL,
+  `allowed_ips` text,
+  `expires_at` datetime,
+  `last_used_at` datetime,
+  `last_used_ip` text,
+  `revoked_at` datetime,
+  `created_by_id` integer NOT NULL,
+  `created_at` datetime,
+  CONSTRAINT `fk_api_keys_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `uni_api_keys_key_hash` UNIQUE (`key_hash`)
+);
+CREATE INDEX `idx_api_keys_created_by_id` ON `api_keys`(`created_by_id`);
+CREATE INDEX `idx_api_keys_prefix` ON `api_keys`(`prefix`);
+
+CREATE TABLE `api_key_scopes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `api_key_id` integer NOT NULL,
+  `permission` text NOT NULL,
+  CONSTRAINT `fk_api_keys_scopes` FOREIGN KEY (`api_key_id`) REFERENCES `api_keys`(`id`)
+);
+CREATE UNIQUE INDEX `idx_api_key_scopes_key_permission` ON `api_key_scopes`(`api_key_id`,`permission`);
+
+CREATE TABLE `audit_logs` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `actor_id` integer,
+  `api_key_id` integer,
+  `entity` text NOT NULL,
+  `entity_id` text,
+  `action` text NOT NULL,
+  `method` text NOT NULL,
+  `path` text NOT NULL,
+  `status` integer,
+  `before` text,
+  `after` text,
+  `changes` text,
+  `ip` text,
+  `request_id` text,
+  `created_at` datetime,
+  CONSTRAINT `fk_audit_logs_actor` FOREIGN KEY (`actor_id`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX `idx_audit_logs_created_at` ON `audit_logs`(`created_at`);
+CREATE INDEX `idx_audit_logs_request_id` ON `audit_logs`(`request_id`);
+CREATE INDEX `idx_audit_logs_entity` ON `audit_logs`(`entity`,`entity_id`);
+CREATE INDEX `idx_audit_logs_api_key_id` ON `audit_logs`(`api_key_id`);
+CREATE INDEX `idx_audit_logs_actor_id` ON `audit_logs`(`actor_id`);
+
+CREATE TABLE `scheduled_price_changes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_id` integer NOT NULL,
+  `new_price` real NOT NULL,
+  `effective_at` datetime NOT NULL,
+  `status` text NOT NULL DEFAULT 'pending',
+  `note` text,
+  `created_by_id` integer NOT NULL,
+  `applied_at` datetime,
+  `created_at` datetime,
+  `updated_at` datetime,
+  CONSTRAINT `fk_scheduled_price_changes_product` FOREIGN KEY (`product_id`) REFERENCES `products`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `fk_scheduled_price_changes_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE CASCADE,
+  CONSTRAINT `chk_scheduled_price_changes_new_price` CHECK (new_price>=0)
+);
+CREATE INDEX `idx_scheduled_price_changes_due` ON `scheduled_price_changes`(`effective_at`,`status`);
+CREATE INDEX `idx_scheduled_price_changes_product_id` ON `scheduled_price_changes`(`product_id`);
@@ -0,0 +1 @@
+-- 0003_activity_status_checks.down.sql
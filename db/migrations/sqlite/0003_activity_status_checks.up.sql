@@ -0,0 +1,4 @@
+-- 0003_activity_status_checks.up.sql
+-- Nothing to do: on this database the activity status columns are created as
+-- varchar with check constraints by 0001 and 0002. Kept so versions match
+-- across drivers.
@@ -0,0 +1,6 @@
+-- 0004_create_suppliers.down.sql
+
+DROP INDEX `idx_products_supplier_id`;
+ALTER TABLE `products` DROP COLUMN `supplier_id`;
+
+DROP TABLE `suppliers`;
@@ -0,0 +1,18 @@
+-- 0004_create_suppliers.up.sql
+-- Suppliers, and the supplier each product is bought from
+
+CREATE TABLE `suppliers` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` text NOT NULL,
+  `phone` text,
+  `address` text,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `uni_suppliers_name` UNIQUE (`name`)
+);
+CREATE INDEX `idx_suppliers_deleted_at` ON `suppliers`(`deleted_at`);
+
+-- SQLite can only add a foreign key together with the column
+ALTER TABLE `products` ADD COLUMN `supplier_id` integer REFERENCES `suppliers`(`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+CREATE INDEX `idx_products_supplier_id` ON `products`(`supplier_id`);
@@ -0,0 +1,401 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The migrations are kept per driver in db/migrations/<dialect>, with the same
+// version numbers in every directory.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockName is the advisory lock that keeps two instances from migrating at once
+const migrationLockName = "bengkel_inventory_migrate"
+
+// migrationLockTimeout is how long a run waits for another one to finish
+const migrationLockTimeout = 60 * time.Second
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered pair of up/down SQL files from db/migrations
+type Migration struct {
+	Version  uint
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// SchemaVersion records an applied migration together with the checksum of
+// its up file, so edits to an applied migration are detected.
+type SchemaVersion struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	Checksum  string    `gorm:"size:64;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// MigrationStatus is the state of a migration: applied, pending, modified
+// (the file changed after it was applied) or missing (applied but the file is gone)
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back the SQL migrations
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations embedded in the binary for the driver of db
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", db.Dialector.Name()))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// withContext returns a migrator whose queries are bound to ctx
+func (m *Migrator) withContext(ctx context.Context) *Migrator {
+	return &Migrator{db: m.db.WithContext(ctx), migrations: m.migrations}
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, e := range entries {
+		m := migrationFileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[uint(version)]
+		if mig == nil {
+			mig = &Migration{Version: uint(version), Name: m[2]}
+			byVersion[uint(version)] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+			sum := sha256.Sum256(content)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns how many ran
+func (m *Migrator) Up() (int, error) {
+	count := 0
+	err := m.locked(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		if len(applied) == 0 && conn.Migrator().HasTable("users") {
+			return errors.New("database was created by AutoMigrate before versioned migrations; record it with `migrate baseline 1` and run `migrate up` again")
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.run(conn, mig, mig.Up, func(tx *gorm.DB) error {
+				return tx.Create(&SchemaVersion{
+					Version:   mig.Version,
+					Name:      mig.Name,
+					Checksum:  mig.Checksum,
+					AppliedAt: time.Now(),
+				}).Error
+			}); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations, newest first
+func (m *Migrator) Down(steps int) (int, error) {
+	count := 0
+	err := m.locked(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(mig.Down) == "" {
+				return fmt.Errorf("migration %04d_%s has no down file", mig.Version, mig.Name)
+			}
+			if err := m.run(conn, mig, mig.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&SchemaVersion{}, mig.Version).Error
+			}); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status lists every known migration and every applied one, in version order
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationStatus
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name, State: "pending"}
+		if v, ok := applied[mig.Version]; ok {
+			status.State = "applied"
+			if v.Checksum != mig.Checksum {
+				status.State = "modified"
+			}
+			status.AppliedAt = &v.AppliedAt
+			delete(applied, mig.Version)
+		}
+		result = append(result, status)
+	}
+	for _, v := range applied {
+		appliedAt := v.AppliedAt
+		result = append(result, MigrationStatus{Version: v.Version, Name: v.Name, State: "missing", AppliedAt: &appliedAt})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Pending counts the migrations that still have to run. An applied migration
+// that was edited or whose file is gone is reported as an error.
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, s := range statuses {
+		switch s.State {
+		case "pending":
+			pending++
+		case "modified", "missing":
+			return 0, fmt.Errorf("migration %04d_%s is %s", s.Version, s.Name, s.State)
+		}
+	}
+	return pending, nil
+}
+
+// Baseline records the migrations up to version as applied without running
+// them. It is meant for databases created by the old GORM AutoMigrate.
+func (m *Migrator) Baseline(version uint) error {
+	return m.locked(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("database already has applied migrations")
+		}
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			for _, mig := range m.migrations {
+				if mig.Version > version {
+					break
+				}
+				if err := tx.Create(&SchemaVersion{
+					Version:   mig.Version,
+					Name:      mig.Name,
+					Checksum:  mig.Checksum,
+					AppliedAt: time.Now(),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// run executes the statements of one migration file and then records the
+// result. PostgreSQL and SQLite roll a failed migration back completely; MySQL
+// commits DDL implicitly, so there a migration that fails halfway has to be
+// repaired by hand before it is run again.
+func (m *Migrator) run(conn *gorm.DB, mig Migration, script string, record func(tx *gorm.DB) error) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return record(tx)
+	})
+}
+
+// applied returns the recorded migrations, creating the version table first if needed
+func (m *Migrator) applied(conn *gorm.DB) (map[uint]SchemaVersion, error) {
+	if !conn.Migrator().HasTable(&SchemaVersion{}) {
+		if err := conn.Migrator().CreateTable(&SchemaVersion{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var versions []SchemaVersion
+	if err := conn.Order("version").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint]SchemaVersion, len(versions))
+	for _, v := range versions {
+		applied[v.Version] = v
+	}
+	return applied, nil
+}
+
+// verify refuses to migrate when an applied migration was edited or removed
+func (m *Migrator) verify(applied map[uint]SchemaVersion) error {
+	known := make(map[uint]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	for version, v := range applied {
+		mig, ok := known[version]
+		if !ok {
+			return fmt.Errorf("migration %04d_%s is applied but its file is missing", v.Version, v.Name)
+		}
+		if mig.Checksum != v.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied", mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+// locked runs fn on a single connection that holds the migration lock
+func (m *Migrator) locked(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		switch conn.Dialector.Name() {
+		case "mysql":
+			var got *int
+			if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&got).Error; err != nil {
+				return err
+			}
+			if got == nil || *got != 1 {
+				return errors.New("another migration is running")
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName)
+
+		case "postgres":
+			if err := conn.Exec(fmt.Sprintf("SET lock_timeout = %d", migrationLockTimeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+			if err := conn.Exec("SELECT pg_advisory_lock(hashtext(?))", migrationLockName).Error; err != nil {
+				return fmt.Errorf("another migration is running: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", migrationLockName)
+			defer conn.Exec("SET lock_timeout = 0")
+
+			// SQLite allows only one writer at a time, which serializes migrations already
+		}
+
+		return fn(conn)
+	})
+}
+
+// splitStatements splits a SQL script on the semicolons outside of quotes and
+// drops "--" comments, so no multi-statement support is needed from the driver.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
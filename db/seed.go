@@ -0,0 +1,52 @@
+package db
+
+import (
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// defaultRoles keeps the behaviour from before permissions existed: admins can
+// do everything, karyawan can record sales and read reports.
+var defaultRoles = map[string][]string{
+	models.RoleAdmin:    models.AllPermissions,
+	models.RoleKaryawan: {models.PermSaleCreate, models.PermReportSales},
+}
+
+var defaultRoleDescriptions = map[string]string{
+	models.RoleAdmin:    "Pemilik / administrator bengkel",
+	models.RoleKaryawan: "Karyawan yang melayani penjualan",
+}
+
+// SeedRoles creates the built-in roles when they do not exist yet. Roles that
+// already exist are left alone so runtime edits survive restarts.
+func SeedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for name, perms := range defaultRoles {
+			var count int64
+			if err := tx.Model(&models.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			// Two-factor stays off until an admin turns it on for the role
+			role := models.Role{
+				Name:             name,
+				Description:      defaultRoleDescriptions[name],
+				RequireTwoFactor: false,
+				CreatedAt:        time.Now(),
+				UpdatedAt:        time.Now(),
+			}
+			for _, p := range perms {
+				role.Permissions = append(role.Permissions, models.RolePermission{Permission: p})
+			}
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
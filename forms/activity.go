@@ -8,6 +8,10 @@ type ProductItem struct {
 
 // ActivityForm ...
 type ActivityForm struct {
-	Products []ProductItem `json:"products" binding:"required,dive,required"`
-	Type	 string        `json:"type" binding:"required,oneof=outbound inbound"`
-}
\ No newline at end of file
+	Products      []ProductItem `json:"products" binding:"required,dive,required"`
+	Type          string        `json:"type" binding:"required,oneof=outbound inbound"`
+	Branch        string        `json:"branch" binding:"omitempty,max=32,branch"`
+	CustomerName  string        `json:"customer_name" binding:"omitempty,max=255"`
+	VehiclePlate  string        `json:"vehicle_plate" binding:"omitempty,max=20,plate"`
+	PaymentStatus string        `json:"payment_status" binding:"omitempty,oneof=paid unpaid"`
+}
@@ -0,0 +1,11 @@
+package forms
+
+import "time"
+
+// APIKeyForm ...
+type APIKeyForm struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string   `json:"allowed_ips" binding:"omitempty,dive,ip|cidr"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
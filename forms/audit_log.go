@@ -0,0 +1,11 @@
+package forms
+
+// AuditLogQueryForm holds the filters of GET /audit-logs
+type AuditLogQueryForm struct {
+	Entity   string `form:"entity" binding:"omitempty,max=50"`
+	EntityID string `form:"entity_id" binding:"omitempty,max=100"`
+	Action   string `form:"action" binding:"omitempty,max=50"`
+	UserID   *uint  `form:"user_id"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
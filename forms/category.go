@@ -2,5 +2,11 @@ package forms
 
 // RegisterForm ...
 type CategoryForm struct {
-	Name       string  `json:"name" binding:"required"`
-}
\ No newline at end of file
+	Name     string `json:"name" binding:"required"`
+	ParentID *uint  `json:"parent_id" binding:"omitempty,gt=0"`
+}
+
+// MoveCategoryForm moves a category under another parent, or to the top level when ParentID is null
+type MoveCategoryForm struct {
+	ParentID *uint `json:"parent_id" binding:"omitempty,gt=0"`
+}
@@ -0,0 +1,18 @@
+package forms
+
+// FitmentForm ...
+type FitmentForm struct {
+	Make     string `json:"make" binding:"required,max=100"`
+	Model    string `json:"model" binding:"required,max=100"`
+	YearFrom int    `json:"year_from" binding:"required,gte=1900,lte=2100"`
+	YearTo   *int   `json:"year_to" binding:"omitempty,gtefield=YearFrom,lte=2100"`
+}
+
+// FitmentSearchForm selects a vehicle either directly or from the registry
+type FitmentSearchForm struct {
+	VehicleID uint   `form:"vehicle_id"`
+	Plate     string `form:"plate"`
+	Make      string `form:"make" binding:"required_without_all=VehicleID Plate"`
+	Model     string `form:"model" binding:"required_without_all=VehicleID Plate"`
+	Year      int    `form:"year" binding:"required_without_all=VehicleID Plate"`
+}
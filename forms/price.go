@@ -0,0 +1,22 @@
+package forms
+
+import "time"
+
+// ScheduledPriceForm ...
+type ScheduledPriceForm struct {
+	NewPrice    *float64  `json:"new_price" binding:"required,gte=0"`
+	EffectiveAt time.Time `json:"effective_at" binding:"required"`
+	Note        string    `json:"note" binding:"omitempty,max=255"`
+}
+
+// BulkPriceForm selects products by category, supplier and/or ID and shifts their
+// price by a percentage or a fixed amount, rounded to the nearest round_to rupiah
+type BulkPriceForm struct {
+	CategoryID uint    `json:"category_id" binding:"omitempty,min=1"`
+	SupplierID uint    `json:"supplier_id" binding:"omitempty,min=1"`
+	ProductIDs []uint  `json:"product_ids" binding:"omitempty,max=1000,dive,min=1"`
+	Mode       string  `json:"mode" binding:"required,oneof=percent amount"`
+	Value      float64 `json:"value" binding:"required"`
+	RoundTo    int     `json:"round_to" binding:"omitempty,oneof=100 500 1000"`
+	DryRun     bool    `json:"dry_run"`
+}
@@ -3,15 +3,36 @@ package forms
 // RegisterForm ...
 type ProductForm struct {
 	Name       string  `json:"name" binding:"required"`
+	SKU        string  `json:"sku" binding:"omitempty,max=64,sku"`
+	Brand      string  `json:"brand" binding:"omitempty,max=100"`
 	Stock      int     `json:"stock" binding:"required"`
 	Price      float64 `json:"price" binding:"required"`
 	Location   string  `json:"location" binding:"required"`
 	CategoryID uint    `json:"category_id" binding:"required"`
+	SupplierID *uint   `json:"supplier_id" binding:"omitempty,min=1"`
 }
 
 type UpdateProductForm struct {
 	Name       string  `json:"name" binding:"required"`
+	SKU        string  `json:"sku" binding:"omitempty,max=64,sku"`
+	Brand      string  `json:"brand" binding:"omitempty,max=100"`
 	Price      float64 `json:"price" binding:"required"`
 	Location   string  `json:"location" binding:"required"`
 	CategoryID uint    `json:"category_id" binding:"required"`
-}
\ No newline at end of file
+	SupplierID *uint   `json:"supplier_id" binding:"omitempty,min=1"`
+}
+
+// ProductQueryForm holds the search, filter and sort parameters of GET /products
+type ProductQueryForm struct {
+	Search            string   `form:"search" binding:"omitempty,max=100"`
+	CategoryID        uint     `form:"category_id"`
+	Location          string   `form:"location"`
+	MinStock          *int     `form:"min_stock"`
+	MaxStock          *int     `form:"max_stock"`
+	MinPrice          *float64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice          *float64 `form:"max_price" binding:"omitempty,gte=0"`
+	LowStock          bool     `form:"low_stock"`
+	LowStockThreshold int      `form:"low_stock_threshold" binding:"omitempty,gte=0"`
+	Sort              string   `form:"sort" binding:"omitempty,oneof=name price stock last_sold"`
+	Order             string   `form:"order" binding:"omitempty,oneof=asc desc"`
+}
@@ -0,0 +1,9 @@
+package forms
+
+// ReceiptTemplateForm ...
+type ReceiptTemplateForm struct {
+	WorkshopName string `json:"workshop_name" binding:"required,max=255"`
+	Address      string `json:"address" binding:"max=255"`
+	Phone        string `json:"phone" binding:"omitempty,max=50,phone"`
+	Footer       string `json:"footer" binding:"max=500"`
+}
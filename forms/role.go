@@ -0,0 +1,13 @@
+package forms
+
+// RoleForm ...
+type RoleForm struct {
+	Name        string   `json:"name" binding:"required,max=50,alphanum"`
+	Description string   `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// RolePermissionsForm ...
+type RolePermissionsForm struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
@@ -0,0 +1,8 @@
+package forms
+
+// SupplierForm ...
+type SupplierForm struct {
+	Name    string `json:"name" binding:"required,max=255"`
+	Phone   string `json:"phone" binding:"omitempty,max=50"`
+	Address string `json:"address" binding:"omitempty,max=255"`
+}
@@ -0,0 +1,327 @@
+package forms
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLocale is used when Accept-Language names no supported language
+const DefaultLocale = "en"
+
+var uni *ut.UniversalTranslator
+
+// translation is a message for a validation tag; params builds the values for
+// {1}, {2}... after the field name in {0}
+type translation struct {
+	tag    string
+	text   string
+	params func(fe validator.FieldError) []string
+}
+
+// messages are the texts used for binding errors that do not come from the
+// validator, keyed by locale
+var messages = map[string]map[string]string{
+	"en": {
+		"invalid_request": "Invalid request",
+		"invalid_json":    "Request body is not valid JSON",
+		"empty_body":      "Request body is empty",
+		"invalid_field":   "{0} is invalid",
+		"wrong_type":      "{0} must be {1}",
+		"type_number":     "a number",
+		"type_string":     "text",
+		"type_bool":       "true or false",
+		"type_array":      "a list",
+		"type_object":     "an object",
+	},
+	"id": {
+		"invalid_request": "Request tidak valid",
+		"invalid_json":    "Body request bukan JSON yang valid",
+		"empty_body":      "Body request kosong",
+		"invalid_field":   "{0} tidak valid",
+		"wrong_type":      "{0} harus berupa {1}",
+		"type_number":     "angka",
+		"type_string":     "teks",
+		"type_bool":       "true atau false",
+		"type_array":      "daftar",
+		"type_object":     "objek",
+	},
+}
+
+// translations replace or add to the default validator translations, mostly
+// so that field parameters use the JSON names clients send
+var translations = map[string][]translation{
+	"en": {
+		{tag: "eqfield", text: "{0} must be equal to {1}", params: fieldParam},
+		{tag: "gtefield", text: "{0} must be greater than or equal to {1}", params: fieldParam},
+		{tag: "required_without_all", text: "{0} is required when {1} is not given", params: fieldParam},
+		{tag: "datetime", text: "{0} must use the {1} format", params: layoutParam},
+		{tag: "ip|cidr", text: "{0} must be an IP address or a CIDR range"},
+		{tag: "fullname", text: "{0} must be 3 to 20 characters without digits or symbols"},
+		{tag: "phone", text: "{0} must be an Indonesian phone number, e.g. 081234567890"},
+		{tag: "plate", text: "{0} must be a plate number, e.g. B 1234 XYZ"},
+		{tag: "sku", text: "{0} may only contain letters and digits separated by -, _ or ."},
+		{tag: "branch", text: "{0} may only contain letters and digits separated by -, _ or ."},
+	},
+	"id": {
+		{tag: "eqfield", text: "{0} harus sama dengan {1}", params: fieldParam},
+		{tag: "gtefield", text: "{0} harus lebih besar dari atau sama dengan {1}", params: fieldParam},
+		{tag: "required_without_all", text: "{0} wajib diisi jika {1} tidak diisi", params: fieldParam},
+		{tag: "datetime", text: "{0} harus berformat {1}", params: layoutParam},
+		{tag: "ip|cidr", text: "{0} harus berupa alamat IP atau rentang CIDR"},
+		{tag: "fullname", text: "{0} harus 3 hingga 20 karakter tanpa angka atau simbol"},
+		{tag: "phone", text: "{0} harus berupa nomor telepon Indonesia, misalnya 081234567890"},
+		{tag: "plate", text: "{0} harus berupa nomor polisi, misalnya B 1234 XYZ"},
+		{tag: "sku", text: "{0} hanya boleh berisi huruf dan angka yang dipisah -, _ atau ."},
+		{tag: "branch", text: "{0} hanya boleh berisi huruf dan angka yang dipisah -, _ atau ."},
+	},
+}
+
+// RegisterTranslations sets up the English and Indonesian messages for v, the
+// validator gin binds requests with, and reports fields by their JSON names.
+func RegisterTranslations(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	english, indonesian := en.New(), id.New()
+	uni = ut.New(english, english, indonesian)
+
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"id": id_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range defaults {
+		trans, _ := uni.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			return err
+		}
+		for key, text := range messages[locale] {
+			if err := trans.Add(key, text, false); err != nil {
+				return err
+			}
+		}
+		for _, t := range translations[locale] {
+			if err := registerTranslation(v, trans, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func registerTranslation(v *validator.Validate, trans ut.Translator, t translation) error {
+	return v.RegisterTranslation(t.tag, trans,
+		func(trans ut.Translator) error {
+			return trans.Add(t.tag, t.text, true)
+		},
+		func(trans ut.Translator, fe validator.FieldError) string {
+			params := []string{fe.Field()}
+			if t.params != nil {
+				params = append(params, t.params(fe)...)
+			}
+			msg, err := trans.T(t.tag, params...)
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+}
+
+// ValidationMessage is a binding error translated for the client: Message
+// summarizes it and Fields holds one message per invalid field.
+type ValidationMessage struct {
+	Locale  string
+	Message string
+	Fields  map[string]string
+}
+
+// TranslateError translates a request binding error into the language the
+// Accept-Language header asks for, falling back to English.
+func TranslateError(err error, acceptLanguage string) ValidationMessage {
+	locale, trans := translator(acceptLanguage)
+	result := ValidationMessage{Locale: locale, Message: text(trans, "invalid_request")}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		result.Fields = make(map[string]string, len(validationErrs))
+		for i, fe := range validationErrs {
+			msg := fe.Error()
+			if trans != nil {
+				msg = fe.Translate(trans)
+			}
+			// Tags without a translation come back as the raw validator message
+			if msg == fe.Error() {
+				msg = text(trans, "invalid_field", fe.Field())
+			}
+			result.Fields[fieldPath(fe)] = msg
+			if i == 0 {
+				result.Message = msg
+			}
+		}
+
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		msg := text(trans, "wrong_type", field, text(trans, "type_"+jsonType(typeErr.Type)))
+		result.Message = msg
+		result.Fields = map[string]string{field: msg}
+
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		result.Message = text(trans, "invalid_json")
+
+	case errors.Is(err, io.EOF):
+		result.Message = text(trans, "empty_body")
+	}
+
+	return result
+}
+
+// translator picks the first supported language from an Accept-Language
+// header, honouring q-values
+func translator(acceptLanguage string) (string, ut.Translator) {
+	if uni == nil {
+		return DefaultLocale, nil
+	}
+
+	type lang struct {
+		tag string
+		q   float64
+	}
+	var langs []lang
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v := strings.TrimSpace(f); strings.HasPrefix(v, "q=") {
+				if parsed, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, lang{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	for _, l := range langs {
+		// "id-ID" and "en_US" are served by their base language
+		parts := strings.FieldsFunc(l.tag, func(r rune) bool { return r == '-' || r == '_' })
+		if len(parts) == 0 {
+			continue
+		}
+		if trans, found := uni.GetTranslator(parts[0]); found {
+			return parts[0], trans
+		}
+	}
+	trans, _ := uni.GetTranslator(DefaultLocale)
+	return DefaultLocale, trans
+}
+
+// text looks up one of the messages, in English when no translator is set up
+func text(trans ut.Translator, key string, params ...string) string {
+	if trans != nil {
+		if msg, err := trans.T(key, params...); err == nil {
+			return msg
+		}
+	}
+	msg := messages[DefaultLocale][key]
+	for i, p := range params {
+		msg = strings.Replace(msg, "{"+strconv.Itoa(i)+"}", p, 1)
+	}
+	return msg
+}
+
+// fieldName is the name a field is reported under: its JSON name, or its
+// query name for forms bound from the query string
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(f.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// fieldPath is the path of a field below the form, e.g. "items[0].quantity"
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldParam turns the struct field names of a tag parameter like
+// "VehicleID Plate" into the JSON names clients know
+func fieldParam(fe validator.FieldError) []string {
+	names := strings.Fields(fe.Param())
+	for i, n := range names {
+		names[i] = snakeCase(n)
+	}
+	return []string{strings.Join(names, ", ")}
+}
+
+var layoutReplacer = strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD", "15", "hh", "04", "mm", "05", "ss")
+
+// layoutParam shows a Go time layout the way people write date formats
+func layoutParam(fe validator.FieldError) []string {
+	return []string{layoutReplacer.Replace(fe.Param())}
+}
+
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func jsonType(t reflect.Type) string {
+	if t == nil {
+		return "object"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
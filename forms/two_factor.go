@@ -0,0 +1,28 @@
+package forms
+
+// TwoFactorLoginForm ...
+type TwoFactorLoginForm struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=20"`
+}
+
+// LoginChallengeForm ...
+type LoginChallengeForm struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorCodeForm ...
+type TwoFactorCodeForm struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+// DisableTwoFactorForm ...
+type DisableTwoFactorForm struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+// RoleTwoFactorForm ...
+type RoleTwoFactorForm struct {
+	Required *bool `json:"required" binding:"required"`
+}
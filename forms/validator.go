@@ -7,16 +7,83 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	spaces = regexp.MustCompile(`\s+`)
+
+	//To support all possible languages
+	fullNamePattern = regexp.MustCompile(`^[^±!@£$%^&*_+§¡€#¢§¶•ªº«\\/<>?:;'"|=.,0123456789]{3,20}$`)
+
+	// Indonesian numbers: 08xx, 628xx or +628xx (mobile) and 0xx / 62xx / +62xx
+	// (landline with area code), 9 to 13 digits after the prefix
+	phonePattern = regexp.MustCompile(`^(\+62|62|0)[1-9][0-9]{7,12}$`)
+
+	// Indonesian plates: region code, number and an optional suffix ("B 1234 XYZ")
+	platePattern = regexp.MustCompile(`^[A-Z]{1,2} ?[0-9]{1,4}( ?[A-Z]{1,3})?$`)
+
+	// Letters and digits in groups joined by "-", "_" or "." ("BRK-PAD-001")
+	skuPattern = regexp.MustCompile(`^[A-Za-z0-9]+([-_.][A-Za-z0-9]+)*$`)
+
+	// Branch codes go into invoice numbers ("INV/jkt-1/2026/10/00042"), so
+	// they follow the SKU rules and can never contain "/". The number adds 18
+	// characters to the branch and has to fit in 50, so branches are at most 32.
+	branchPattern = skuPattern
+)
+
+// RegisterValidators adds the project's custom tags to v, the validator gin
+// binds requests with: fullname, phone, plate, sku and branch
+func RegisterValidators(v *validator.Validate) error {
+	validators := map[string]validator.Func{
+		"fullname": ValidateFullName,
+		"phone":    ValidatePhone,
+		"plate":    ValidatePlate,
+		"sku":      ValidateSKU,
+		"branch":   ValidateBranch,
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //ValidateFullName implements validator.Func
 func ValidateFullName(fl validator.FieldLevel) bool {
 	//Remove the extra space
-	space := regexp.MustCompile(`\s+`)
-	name := space.ReplaceAllString(fl.Field().String(), " ")
+	name := spaces.ReplaceAllString(fl.Field().String(), " ")
 
 	//Remove trailing spaces
 	name = strings.TrimSpace(name)
 
-	//To support all possible languages
-	matched, _ := regexp.Match(`^[^±!@£$%^&*_+§¡€#¢§¶•ªº«\\/<>?:;'"|=.,0123456789]{3,20}$`, []byte(name))
-	return matched
-}
\ No newline at end of file
+	return fullNamePattern.MatchString(name)
+}
+
+// ValidatePhone accepts Indonesian phone numbers; spaces, dashes and
+// parentheses between the digits are ignored
+func ValidatePhone(fl validator.FieldLevel) bool {
+	phone := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '(' || r == ')' {
+			return -1
+		}
+		return r
+	}, fl.Field().String())
+
+	return phonePattern.MatchString(phone)
+}
+
+// ValidatePlate accepts Indonesian plate numbers in any case and spacing,
+// the way service.NormalizePlate stores them
+func ValidatePlate(fl validator.FieldLevel) bool {
+	plate := strings.Join(strings.Fields(strings.ToUpper(fl.Field().String())), " ")
+	return platePattern.MatchString(plate)
+}
+
+// ValidateSKU implements validator.Func
+func ValidateSKU(fl validator.FieldLevel) bool {
+	return skuPattern.MatchString(fl.Field().String())
+}
+
+// ValidateBranch implements validator.Func
+func ValidateBranch(fl validator.FieldLevel) bool {
+	return branchPattern.MatchString(fl.Field().String())
+}
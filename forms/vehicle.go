@@ -0,0 +1,10 @@
+package forms
+
+// VehicleForm ...
+type VehicleForm struct {
+	PlateNumber string `json:"plate_number" binding:"required,max=20,plate"`
+	OwnerName   string `json:"owner_name" binding:"omitempty,max=255"`
+	Make        string `json:"make" binding:"required,max=100"`
+	Model       string `json:"model" binding:"required,max=100"`
+	Year        int    `json:"year" binding:"required,gte=1900,lte=2100"`
+}
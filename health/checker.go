@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds each dependency check so a hanging one cannot stall the probe
+const checkTimeout = 2 * time.Second
+
+// Check reports whether one dependency is usable
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one check in a readiness report
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the readiness of the server and of each of its dependencies
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the registered dependency checks for the readiness probe
+type Checker struct {
+	readiness *Readiness
+	names     []string
+	checks    map[string]Check
+}
+
+func NewChecker(readiness *Readiness) *Checker {
+	return &Checker{readiness: readiness, checks: make(map[string]Check)}
+}
+
+// Add registers a check under a name shown in the report
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// Ready runs every check concurrently. The server is ready when it is not
+// starting or shutting down and every check passed.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	report := Report{Status: "ok", Checks: make(map[string]CheckResult, len(c.names))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, c.checks[name])
+	}
+	wg.Wait()
+
+	ok := true
+	for _, result := range report.Checks {
+		if result.Status != "ok" {
+			ok = false
+		}
+	}
+	if !c.readiness.Ready() {
+		report.Status = "not_ready"
+		return report, false
+	}
+	if !ok {
+		report.Status = "fail"
+	}
+	return report, ok
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    "ok",
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
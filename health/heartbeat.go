@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Heartbeat is recorded by a background worker after every run, so the
+// readiness probe can tell whether it is still alive and whether it works.
+type Heartbeat struct {
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	last    time.Time
+	lastErr error
+}
+
+// NewHeartbeat creates a heartbeat that counts as stalled when no run was
+// recorded for maxAge
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{maxAge: maxAge}
+}
+
+// Record notes a finished run and its error, if any
+func (h *Heartbeat) Record(err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.last, h.lastErr = time.Now(), err
+	h.mu.Unlock()
+}
+
+// Check implements Check
+func (h *Heartbeat) Check(ctx context.Context) error {
+	h.mu.Lock()
+	last, lastErr := h.last, h.lastErr
+	h.mu.Unlock()
+
+	switch {
+	case last.IsZero():
+		return fmt.Errorf("worker has not run yet")
+	case time.Since(last) > h.maxAge:
+		return fmt.Errorf("worker has not run since %s", last.Format(time.RFC3339))
+	case lastErr != nil:
+		return fmt.Errorf("last run failed: %w", lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package health tracks whether the server can take traffic.
+package health
+
+import "sync/atomic"
+
+// Readiness is set once the server has started and cleared as soon as it
+// begins shutting down, so load balancers stop routing new requests to it
+// while in-flight ones drain.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
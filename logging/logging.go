@@ -0,0 +1,116 @@
+// Package logging sets up the structured application log. Records go to
+// stdout as JSON (or text); the request and caller stored in a context are
+// added to every record logged with that context, and attributes that look
+// like credentials are masked.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/sinscostank/bengkel-inventory/config"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	callerKey
+)
+
+type caller struct {
+	userID   uint
+	apiKeyID uint
+}
+
+// sensitiveKeys mask any attribute whose key contains one of them
+var sensitiveKeys = []string{
+	"password", "token", "secret", "authorization", "cookie",
+	"api_key", "dsn", "totp", "recovery_code",
+}
+
+// Setup builds the logger described by cfg and makes it the default for slog
+// and the standard log package. An unknown level falls back to info.
+func Setup(cfg config.Log) *slog.Logger {
+	logger := New(os.Stdout, cfg)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New builds a logger writing to w
+func New(w io.Writer, cfg config.Log) *slog.Logger {
+	level, err := cfg.ParseLevel()
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: mask}
+
+	var handler slog.Handler
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// WithRequestID returns a context whose log records carry request_id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithCaller returns a context whose log records carry the authenticated
+// user_id, or api_key_id for integrations
+func WithCaller(ctx context.Context, userID, apiKeyID uint) context.Context {
+	return context.WithValue(ctx, callerKey, caller{userID, apiKeyID})
+}
+
+// contextHandler adds the request ID and caller of the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if c, ok := ctx.Value(callerKey).(caller); ok {
+		if c.userID != 0 {
+			r.AddAttrs(slog.Uint64("user_id", uint64(c.userID)))
+		}
+		if c.apiKeyID != 0 {
+			r.AddAttrs(slog.Uint64("api_key_id", uint64(c.apiKeyID)))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func mask(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+	key := strings.ToLower(a.Key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return slog.String(a.Key, "******")
+		}
+	}
+	return a
+}
@@ -2,45 +2,193 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/sinscostank/bengkel-inventory/config"
 	"github.com/sinscostank/bengkel-inventory/db"
 	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/health"
+	"github.com/sinscostank/bengkel-inventory/logging"
+	"github.com/sinscostank/bengkel-inventory/metrics"
+	"github.com/sinscostank/bengkel-inventory/repository"
 	"github.com/sinscostank/bengkel-inventory/route"
+	"github.com/sinscostank/bengkel-inventory/service"
 )
 
 func main() {
 
-	// Create a new validator instance
-	validate := validator.New()
+	// `bengkel-inventory migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		cfg, err := config.Read()
+		if err == nil {
+			err = cfg.Database.Validate()
+		}
+		if err != nil {
+			fatal("loading the configuration failed", err)
+		}
+		logging.Setup(cfg.Log)
+		dbConn, err := db.Connect(cfg.Database)
+		if err != nil {
+			fatal("connecting to the database failed", err)
+		}
+		if err := db.MigrateCommand(dbConn, os.Args[2:]); err != nil {
+			fatal("migrate failed", err)
+		}
+		return
+	}
 
-	// Register the custom validator function
-	validate.RegisterValidation("fullname", forms.ValidateFullName)
+	// 1. Load the configuration (defaults, config.yaml, .env, environment)
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("loading the configuration failed", err)
+	}
 
-	// 1. Load .env (DB creds, PORT, JWT_SECRET, dsb.)
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, relying on environment variables")
+	// Structured logs on stdout; gin's route listing only at debug level
+	logger := logging.Setup(cfg.Log)
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// 2. Inisialisasi koneksi DB & AutoMigrate via GORM
-	dbConn, err := db.InitDB()
+	// 2. Inisialisasi koneksi DB & jalankan migrasi SQL
+	dbConn, err := db.InitDB(cfg.Database)
 	if err != nil {
-		log.Fatalf("Error initializing the database: %v", err)
-		return
+		fatal("initializing the database failed", err)
 	}
 
-	// 3. Buat Gin router
-	router := route.SetupRoutes(dbConn)
+	// 3. Register the custom validators and the Indonesian/English validation
+	// messages on the validator gin binds requests with
+	validate := binding.Validator.Engine().(*validator.Validate)
+	if err := forms.RegisterValidators(validate); err != nil {
+		fatal("registering validators failed", err)
+	}
+	if err := forms.RegisterTranslations(validate); err != nil {
+		fatal("registering validation messages failed", err)
+	}
 
-	// 4. Run the server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// 4. Prometheus metrics, including query durations and pool stats
+	appMetrics := metrics.New()
+	if err := db.RegisterMetrics(dbConn, appMetrics); err != nil {
+		fatal("registering database metrics failed", err)
 	}
-	log.Printf("Server running on http://localhost:%s\n", port)
-	router.Run(":" + port)
+
+	// 5. Readiness checks: database, schema version and the price scheduler,
+	// which counts as stalled after missing two runs
+	readiness := &health.Readiness{}
+	priceHeartbeat := health.NewHeartbeat(2*cfg.Scheduler.PriceInterval + 30*time.Second)
+	migrationCheck, err := db.MigrationCheck(dbConn)
+	if err != nil {
+		fatal("loading migrations failed", err)
+	}
+	checker := health.NewChecker(readiness)
+	checker.Add("database", db.PingCheck(dbConn))
+	checker.Add("migrations", migrationCheck)
+	checker.Add("price_scheduler", priceHeartbeat.Check)
+
+	// 6. Buat Gin router
+	router, err := route.SetupRoutes(dbConn, cfg, checker, appMetrics)
+	if err != nil {
+		fatal("setting up routes failed", err)
+	}
+
+	// 7. Apply scheduled price changes in the background until shutdown
+	jobs, stopJobs := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	priceService := service.NewPriceService(
+		repository.NewProductRepository(dbConn),
+		repository.NewPriceHistoryRepository(dbConn),
+		repository.NewScheduledPriceChangeRepository(dbConn),
+		repository.NewCategoryRepository(dbConn),
+		repository.NewSupplierRepository(dbConn),
+	)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		service.RunPriceScheduler(jobs, priceService, cfg.Scheduler.PriceInterval, priceHeartbeat)
+	}()
+
+	// 8. Run the server
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server running", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	readiness.SetReady(true)
+
+	// 9. On SIGINT/SIGTERM stop taking traffic, let in-flight requests and
+	// the background jobs finish, then close the database
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	serving := true
+	select {
+	case sig := <-stop:
+		slog.Info("shutting down", "signal", sig.String())
+	case err := <-serverErr:
+		slog.Error("server failed", "error", err)
+		serving = false
+	}
+	readiness.SetReady(false)
+
+	// Keep serving until load balancers have seen /readyz fail; a second
+	// signal skips the wait
+	if serving && cfg.Server.DrainDelay > 0 {
+		slog.Info("waiting for load balancers to stop sending traffic", "delay", cfg.Server.DrainDelay.String())
+		select {
+		case <-time.After(cfg.Server.DrainDelay):
+		case sig := <-stop:
+			slog.Warn("skipping the drain delay", "signal", sig.String())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("draining requests failed", "error", err)
+	}
+
+	stopJobs()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("background jobs did not finish before the shutdown timeout")
+	}
+
+	if sqlDB, err := dbConn.DB(); err == nil {
+		sqlDB.Close()
+	}
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,124 @@
+// Package metrics collects the Prometheus metrics served on /metrics: HTTP
+// traffic, database queries and the workshop's business throughput.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bengkel"
+
+// Metrics owns a registry with the Go runtime and process collectors and the
+// application's own metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+
+	salesCreated     prometheus.Counter
+	itemsSold        prometheus.Counter
+	stockAdjustments prometheus.Counter
+	activityFailures *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests answered, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to answer HTTP requests, by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by database queries, by operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+
+		salesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sales_created_total",
+			Help:      "Sales (outbound activities) created.",
+		}),
+		itemsSold: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_sold_total",
+			Help:      "Units of stock sold.",
+		}),
+		stockAdjustments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stock_adjustments_total",
+			Help:      "Stock adjustments (inbound activities) posted.",
+		}),
+		activityFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "activity_failures_total",
+			Help:      "Activity creations that failed, by activity type and error code.",
+		}, []string{"type", "reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.dbDuration,
+		m.salesCreated, m.itemsSold, m.stockAdjustments, m.activityFailures,
+	)
+	return m
+}
+
+// Register adds more collectors, e.g. the connection pool stats
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records an answered request. Requests that matched no route
+// share the route "unmatched" so scanners cannot blow up the label set.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a database query
+func (m *Metrics) ObserveQuery(operation, table string, duration time.Duration) {
+	m.dbDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// ActivityCreated counts a sale and its units, or a stock adjustment
+func (m *Metrics) ActivityCreated(activityType string, quantity int) {
+	switch activityType {
+	case "outbound":
+		m.salesCreated.Inc()
+		m.itemsSold.Add(float64(quantity))
+	case "inbound":
+		m.stockAdjustments.Inc()
+	}
+}
+
+// ActivityFailed counts an activity creation rejected or failed with reason
+func (m *Metrics) ActivityFailed(activityType, reason string) {
+	m.activityFailures.WithLabelValues(activityType, reason).Inc()
+}
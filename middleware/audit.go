@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// AuditRecorder stores audit entries.
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *models.AuditLog, before, after interface{}) error
+}
+
+// bodyRecorder keeps a copy of the response body for the audit log
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Audit records every successful POST, PUT, PATCH and DELETE with the caller,
+// the entity and its state before (when the handler set utils.AuditBeforeKey)
+// and after (the response body). On authenticated routes it must run after
+// AuthMiddleware; on public account routes the handler names the user it acted
+// for through utils.AuditActorKey.
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		// Handlers that failed only attached an error; ErrorHandler answers it later
+		if len(c.Errors) > 0 || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		entity, action := auditEntityAction(c.Request.Method, c.FullPath())
+		entry := &models.AuditLog{
+			Entity:    entity,
+			EntityID:  c.Param("id"),
+			Action:    action,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString(utils.RequestIDKey),
+			CreatedAt: time.Now(),
+		}
+		if entry.EntityID == "" && len(c.Params) > 0 {
+			// Routes keyed by name, e.g. /roles/:name or /receipt-templates/:branch
+			entry.EntityID = c.Params[0].Value
+		}
+		if actorID := c.GetUint(utils.AuditActorKey); actorID != 0 {
+			entry.ActorID = &actorID
+			if entity == "users" {
+				entry.EntityID = strconv.FormatUint(uint64(actorID), 10)
+			}
+		}
+		if claims, ok := c.Value("userClaims").(*utils.UserClaims); ok {
+			if claims.ID != 0 {
+				entry.ActorID = &claims.ID
+			}
+			if claims.APIKeyID != 0 {
+				entry.APIKeyID = &claims.APIKeyID
+			}
+			if entity == "users" && entry.EntityID == "" && strings.HasPrefix(c.FullPath(), "/me/") {
+				entry.EntityID = strconv.FormatUint(uint64(claims.ID), 10)
+			}
+		}
+
+		before, _ := c.Get(utils.AuditBeforeKey)
+		var after interface{}
+		if strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "application/json") {
+			after = json.RawMessage(w.body.Bytes())
+		}
+
+		// The change already happened, so a failed audit write must not fail the
+		// request, and a client hanging up must not cancel it
+		if err := recorder.Record(context.WithoutCancel(c.Request.Context()), entry, before, after); err != nil {
+			slog.ErrorContext(c.Request.Context(), "audit: failed to record", "method", entry.Method, "path", entry.Path, "error", err)
+		}
+	}
+}
+
+// accountRoutes names the entity and action of the account routes whose paths
+// don't follow the /<entity>/... layout
+var accountRoutes = map[string][2]string{
+	"/register":        {"users", "register"},
+	"/refresh":         {"sessions", "refresh"},
+	"/logout":          {"sessions", "logout"},
+	"/logout-all":      {"sessions", "logout_all"},
+	"/password/forgot": {"users", "password_forgot"},
+	"/password/reset":  {"users", "password_reset"},
+}
+
+// auditEntityAction derives the entity and action from a route, e.g.
+// "PUT /categories/:id/move" gives ("categories", "move"), "DELETE /products/:id"
+// gives ("products", "delete") and "POST /me/2fa/enroll" gives ("users", "2fa_enroll")
+func auditEntityAction(method, route string) (string, string) {
+	if ea, ok := accountRoutes[route]; ok {
+		return ea[0], ea[1]
+	}
+
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if segments[0] == "me" && len(segments) > 1 {
+		return "users", strings.Join(segments[1:], "_")
+	}
+
+	// A trailing static segment names the action
+	last := segments[len(segments)-1]
+	if len(segments) > 1 && !strings.HasPrefix(last, ":") {
+		return segments[0], last
+	}
+
+	switch method {
+	case http.MethodPost:
+		return segments[0], "create"
+	case http.MethodPut, http.MethodPatch:
+		return segments[0], "update"
+	case http.MethodDelete:
+		return segments[0], "delete"
+	}
+	return segments[0], strings.ToLower(method)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+)
+
+// ErrorHandler answers the error a handler attached with c.Error. Every error
+// response has the same envelope: {"error": message, "code": code} plus
+// "details" when the error carries any. Request binding errors are translated
+// per field into the language of the Accept-Language header. Errors that are
+// not domain errors are logged and answered with a generic 500.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		RespondError(c, c.Errors.Last().Err)
+	}
+}
+
+// RespondError writes err in the error envelope and aborts the request
+func RespondError(c *gin.Context, err error) {
+	appErr, ok := apperror.As(err)
+	if !ok {
+		slog.ErrorContext(c.Request.Context(), "unhandled error", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		appErr = &apperror.Error{Code: "internal_error", Message: "internal server error"}
+	}
+
+	message, details := appErr.Message, appErr.Details
+	if appErr.Code == "invalid_request" && appErr.Cause != nil {
+		translated := forms.TranslateError(appErr.Cause, c.GetHeader("Accept-Language"))
+		c.Header("Content-Language", translated.Locale)
+		message = translated.Message
+		if translated.Fields != nil {
+			details = gin.H{"fields": translated.Fields}
+		}
+	}
+
+	if appErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+	}
+
+	body := gin.H{"error": message, "code": appErr.Code}
+	if details != nil {
+		body["details"] = details
+	}
+	c.AbortWithStatusJSON(appErr.Status(), body)
+}
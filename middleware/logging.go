@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/logging"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// requestIDPattern limits client-sent request IDs to what is safe to log and echo
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID keeps the X-Request-ID the client sent, or generates one, and
+// returns it in the response header. The ID is stored under utils.RequestIDKey
+// and in the request context, so every log written with c.Request.Context()
+// carries it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			generated, err := utils.RandomToken(12)
+			if err != nil {
+				generated = fmt.Sprintf("%x", time.Now().UnixNano())
+			}
+			id = generated
+		}
+
+		c.Set(utils.RequestIDKey, id)
+		c.Header("X-Request-ID", id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+// RequestLogger logs every request once it is answered, as an error for 5xx,
+// a warning for 4xx and info otherwise. The query string and headers are left
+// out since they may carry tokens. It must run after RequestID.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", c.Writer.Size(),
+			"ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
+		}
+		if appErr := c.Errors.Last(); appErr != nil {
+			attrs = append(attrs, "error", appErr.Error())
+		}
+		slog.Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery answers a panicking handler with a 500 in the error envelope and
+// logs the panic with its stack trace
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		RespondError(c, &apperror.Error{Code: "internal_error", Message: "internal server error"})
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+)
+
+// RequestRecorder records answered requests for the metrics endpoint.
+type RequestRecorder interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// RequestMetrics counts every request and its duration by route template, so
+// /products/1 and /products/2 share the series of /products/:id.
+func RequestMetrics(recorder RequestRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		recorder.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsToken guards the metrics endpoint with "Authorization: Bearer <token>".
+// Without a configured token the endpoint stays closed.
+func MetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			RespondError(c, apperror.Forbidden("metrics_disabled", "Metrics are disabled; set METRICS_TOKEN to enable them"))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			RespondError(c, apperror.Unauthorized("invalid_metrics_token", "Invalid or missing metrics token"))
+			return
+		}
+		c.Next()
+	}
+}
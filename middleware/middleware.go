@@ -1,29 +1,63 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/logging"
 	"github.com/sinscostank/bengkel-inventory/utils"
 )
 
-// AuthMiddleware stub so route.go compiles.
-func AuthMiddleware() gin.HandlerFunc {
+// SessionChecker validates access tokens and reports whether the session
+// behind one was revoked or its user has to change their password.
+type SessionChecker interface {
+	ParseAccessToken(token string) (*utils.UserClaims, error)
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	MustResetPassword(ctx context.Context, userID uint) (bool, error)
+}
+
+// passwordResetRoutes stay open to users who must change their password
+var passwordResetRoutes = map[string]bool{
+	"POST /me/password": true,
+	"POST /logout":      true,
+	"POST /logout-all":  true,
+}
+
+// APIKeyAuthenticator resolves an API key to the claims it acts with.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey, ip string) (*utils.UserClaims, error)
+}
+
+// AuthMiddleware validates the Bearer token and rejects tokens of revoked sessions.
+// Users whose password was reset by an admin can only change it or log out.
+// Integrations may send an API key instead, as "X-API-Key: <key>" or "Authorization: ApiKey <key>".
+func AuthMiddleware(sessions SessionChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			claims, err := apiKeys.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+			if err != nil {
+				RespondError(c, err)
+				return
+			}
+
+			setCaller(c, claims)
+			c.Next()
+			return
+		}
+
 		// Get the "Authorization" header value
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			RespondError(c, apperror.Unauthorized("authorization_required", "Authorization header is required"))
 			return
 		}
 
 		// Split the string into "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
-			c.Abort()
+			RespondError(c, apperror.Unauthorized("invalid_authorization", "Invalid authorization format"))
 			return
 		}
 
@@ -31,48 +65,102 @@ func AuthMiddleware() gin.HandlerFunc {
 		token := parts[1]
 
 		// Validate the token (this should return the user info if the token is valid)
-		userClaims, err := utils.ValidateJWT(token)
+		userClaims, err := sessions.ParseAccessToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			RespondError(c, apperror.Unauthorized("invalid_token", "Invalid or expired token"))
+			return
+		}
+
+		// Reject tokens whose session was logged out or revoked
+		active, err := sessions.IsSessionActive(c.Request.Context(), userClaims.SessionID)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		if !active {
+			RespondError(c, apperror.Unauthorized("session_revoked", "Session has been revoked"))
+			return
+		}
+
+		mustReset, err := sessions.MustResetPassword(c.Request.Context(), userClaims.ID)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		if mustReset && !passwordResetRoutes[c.Request.Method+" "+c.FullPath()] {
+			RespondError(c, apperror.Forbidden("password_reset_required", "You must change your password first"))
 			return
 		}
 
 		// Attach the user claims to the context (for later use in controllers)
-		c.Set("userClaims", userClaims)
+		setCaller(c, userClaims)
 
 		// Continue to the next handler
 		c.Next()
 	}
 }
 
-// AdminMiddleware is a middleware to ensure that only admin users can access a route.
-func AdminMiddleware() gin.HandlerFunc {
+// setCaller stores the claims for the handlers and adds the caller to the
+// request context, so logs written with it carry user_id or api_key_id
+func setCaller(c *gin.Context, claims *utils.UserClaims) {
+	c.Set("userClaims", claims)
+	c.Request = c.Request.WithContext(logging.WithCaller(c.Request.Context(), claims.ID, claims.APIKeyID))
+}
+
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "ApiKey" {
+		return parts[1]
+	}
+	return ""
+}
+
+// UserOnly rejects API key requests, for endpoints that act on the caller's own account or session.
+func UserOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := c.MustGet("userClaims").(*utils.UserClaims); ok && claims.APIKeyID != 0 {
+			RespondError(c, apperror.Forbidden("api_key_not_allowed", "API keys cannot access this endpoint"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PermissionChecker resolves the permissions granted to a caller.
+type PermissionChecker interface {
+	Allows(ctx context.Context, caller *utils.UserClaims, permissions ...string) (bool, error)
+}
+
+// RequirePermission is a middleware to ensure that the user's role, or the API key's scopes, grant all the given permissions.
+func RequirePermission(checker PermissionChecker, permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Retrieve user claims from the context
 		userClaims, exists := c.Get("userClaims")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found"})
-			c.Abort()
+			RespondError(c, apperror.Unauthorized("unauthenticated", "User claims not found"))
 			return
 		}
 
-		// Type assert the userClaims to your user model (make sure it's the correct type)
-		claims, ok := userClaims.(*utils.UserClaims) // assuming UserClaims is the structure that contains role information
+		claims, ok := userClaims.(*utils.UserClaims)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user claims"})
-			c.Abort()
+			RespondError(c, apperror.Unauthorized("unauthenticated", "Invalid user claims"))
 			return
 		}
 
-		// Check if the user's role is "admin"
-		if claims.Role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have the required admin role"})
-			c.Abort()
+		allowed, err := checker.Allows(c.Request.Context(), claims, permissions...)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		if !allowed {
+			RespondError(c, apperror.Forbidden("permission_denied", "You do not have the required permission"))
 			return
 		}
 
 		// Continue to the next handler
 		c.Next()
 	}
-}
\ No newline at end of file
+}
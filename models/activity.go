@@ -8,14 +8,20 @@ import (
 
 // Activity represents a sales transaction header
 type Activity struct {
-	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    uint           `json:"user_id" gorm:"not null;index"`
-	User      User           `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
-	Date      time.Time      `json:"date" gorm:"not null"`
-	Status    string         `json:"status" gorm:"type:enum('success','failed');not null"`
-	Type      string         `json:"type" gorm:"type:enum('inbound','outbound');not null"`	
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
-	Items     []ActivityItem `json:"items" gorm:"foreignKey:ActivityID"`
-}
\ No newline at end of file
+	ID            uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	User          User           `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Branch        string         `json:"branch" gorm:"size:50;not null;default:'default';uniqueIndex:idx_activities_branch_invoice"`
+	InvoiceNumber *string        `json:"invoice_number" gorm:"size:50;uniqueIndex:idx_activities_branch_invoice"`
+	CustomerName  string         `json:"customer_name" gorm:"size:255"`
+	VehiclePlate  string         `json:"vehicle_plate" gorm:"size:20"`
+	TaxRate       float64        `json:"tax_rate" gorm:"not null;default:0;check:tax_rate>=0"`
+	PaymentStatus string         `json:"payment_status" gorm:"size:20;not null;default:'paid';check:chk_activities_payment_status,payment_status IN ('paid','unpaid')"`
+	Date          time.Time      `json:"date" gorm:"not null"`
+	Status        string         `json:"status" gorm:"size:20;not null;check:chk_activities_status,status IN ('success','failed')"`
+	Type          string         `json:"type" gorm:"size:20;not null;check:chk_activities_type,type IN ('inbound','outbound')"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	Items         []ActivityItem `json:"items" gorm:"foreignKey:ActivityID"`
+}
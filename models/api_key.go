@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey lets an integration call the API without a user password. Requests
+// made with a key are attributed to the admin who created it.
+type APIKey struct {
+	ID          uint          `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string        `json:"name" gorm:"size:100;not null"`
+	Prefix      string        `json:"prefix" gorm:"size:16;not null;index"`
+	KeyHash     string        `json:"-" gorm:"size:64;not null;unique"`
+	AllowedIPs  string        `json:"allowed_ips" gorm:"size:500"` // comma separated IPs or CIDRs, empty allows any
+	ExpiresAt   *time.Time    `json:"expires_at"`
+	LastUsedAt  *time.Time    `json:"last_used_at"`
+	LastUsedIP  string        `json:"last_used_ip" gorm:"size:45"`
+	RevokedAt   *time.Time    `json:"revoked_at"`
+	CreatedByID uint          `json:"created_by_id" gorm:"not null;index"`
+	CreatedBy   User          `json:"created_by" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Scopes      []APIKeyScope `json:"scopes" gorm:"foreignKey:APIKeyID"`
+}
+
+// APIKeyScope grants one permission to an API key
+type APIKeyScope struct {
+	ID         uint   `json:"-" gorm:"primaryKey;autoIncrement"`
+	APIKeyID   uint   `json:"-" gorm:"not null;uniqueIndex:idx_api_key_scopes_key_permission"`
+	APIKey     APIKey `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Permission string `json:"permission" gorm:"size:50;not null;uniqueIndex:idx_api_key_scopes_key_permission"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog records a change made through a mutating endpoint
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID   *uint     `json:"actor_id" gorm:"index"`
+	Actor     *User     `json:"actor,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	APIKeyID  *uint     `json:"api_key_id" gorm:"index"`
+	Entity    string    `json:"entity" gorm:"size:50;not null;index:idx_audit_logs_entity"`
+	EntityID  string    `json:"entity_id" gorm:"size:100;index:idx_audit_logs_entity"`
+	Action    string    `json:"action" gorm:"size:50;not null"`
+	Method    string    `json:"method" gorm:"size:10;not null"`
+	Path      string    `json:"path" gorm:"size:255;not null"`
+	Status    int       `json:"status"`
+	Before    string    `json:"before" gorm:"type:text"`
+	After     string    `json:"after" gorm:"type:text"`
+	Changes   string    `json:"changes" gorm:"type:text"`
+	IP        string    `json:"ip" gorm:"size:45"`
+	RequestID string    `json:"request_id" gorm:"size:64;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
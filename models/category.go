@@ -10,8 +10,11 @@ import (
 type Category struct {
 	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name      string         `json:"name" gorm:"size:255;not null;unique"`
+	ParentID  *uint          `json:"parent_id" gorm:"index"`
+	Parent    *Category      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 	Products  []Product      `json:"products" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
-}
\ No newline at end of file
+	Children  []Category     `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+}
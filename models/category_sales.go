@@ -0,0 +1,12 @@
+package models
+
+// CategorySales is the sales report of a category including its subcategories
+type CategorySales struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Level      int    `json:"level"`
+	Products   int    `json:"products"`
+	Stock      int    `json:"stock"`
+	TotalSales int    `json:"total_sales"`
+}
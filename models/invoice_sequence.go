@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// InvoiceSequence keeps the last invoice number issued per branch and year
+type InvoiceSequence struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Branch     string    `json:"branch" gorm:"size:50;not null;uniqueIndex:idx_invoice_sequences_branch_year"`
+	Year       int       `json:"year" gorm:"not null;uniqueIndex:idx_invoice_sequences_branch_year"`
+	LastNumber int       `json:"last_number" gorm:"not null;default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt is the audit record of a single login attempt
+type LoginAttempt struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    *uint     `json:"user_id" gorm:"index"`
+	Email     string    `json:"email" gorm:"size:255;not null;index"`
+	IP        string    `json:"ip" gorm:"size:45;index"`
+	UserAgent string    `json:"user_agent" gorm:"size:255"`
+	Success   bool      `json:"success" gorm:"not null"`
+	Reason    string    `json:"reason" gorm:"size:50"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// Kinds of login throttles
+const (
+	ThrottleAccount = "account"
+	ThrottleIP      = "ip"
+
+	// Password reset requests, counted per email and per IP
+	ThrottleResetAccount = "reset"
+	ThrottleResetIP      = "reset_ip"
+)
+
+// LoginThrottle tracks consecutive failed logins of an account (by email) or an
+// IP, or the password reset requests made for an email or from an IP
+type LoginThrottle struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kind         string     `json:"kind" gorm:"size:10;not null;uniqueIndex:idx_login_throttles_kind_identifier"`
+	Identifier   string     `json:"identifier" gorm:"size:255;not null;uniqueIndex:idx_login_throttles_kind_identifier"`
+	Failures     int        `json:"failures" gorm:"not null;default:0"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single-use token sent by email to reset a password
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
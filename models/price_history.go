@@ -6,6 +6,13 @@ import (
 	"gorm.io/gorm"
 )
 
+// Sources of a price change
+const (
+	PriceSourceManual    = "manual"
+	PriceSourceScheduled = "scheduled"
+	PriceSourceBulk      = "bulk"
+)
+
 // PriceHistory logs price changes for products
 type PriceHistory struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -14,6 +21,9 @@ type PriceHistory struct {
 	OldPrice    float64        `json:"old_price" gorm:"not null;check:old_price>=0"`
 	NewPrice    float64        `json:"new_price" gorm:"not null;check:new_price>=0"`
 	DateChanged time.Time      `json:"date_changed" gorm:"not null"`
+	ChangedByID *uint          `json:"changed_by_id" gorm:"index"`
+	ChangedBy   *User          `json:"changed_by,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Source      string         `json:"source" gorm:"size:20;not null;default:'manual'"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
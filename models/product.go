@@ -10,11 +10,15 @@ import (
 type Product struct {
 	ID         uint               `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name       string             `json:"name" gorm:"size:255;not null"`
+	SKU        *string            `json:"sku" gorm:"column:sku;size:64;uniqueIndex"`
+	Brand      string             `json:"brand" gorm:"size:100;index"`
 	Stock      int                `json:"stock" gorm:"not null;check:stock>=0"`
 	Price      float64            `json:"price" gorm:"not null;check:price>=0"`
 	Location   string             `json:"location" gorm:"size:255;not null"`
 	CategoryID uint               `json:"category_id" gorm:"not null;index"`
 	Category   Category           `json:"category" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	SupplierID *uint              `json:"supplier_id" gorm:"index"`
+	Supplier   *Supplier          `json:"supplier,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	CreatedAt  time.Time          `json:"created_at"`
 	UpdatedAt  time.Time          `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt     `json:"deleted_at" gorm:"index"`
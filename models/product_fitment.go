@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductFitment links a part to the vehicles it fits
+type ProductFitment struct {
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID uint           `json:"product_id" gorm:"not null;index"`
+	Product   Product        `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Make      string         `json:"make" gorm:"size:100;not null;index:idx_fitments_vehicle"`
+	Model     string         `json:"model" gorm:"size:100;not null;index:idx_fitments_vehicle"`
+	YearFrom  int            `json:"year_from" gorm:"not null"`
+	YearTo    *int           `json:"year_to"` // nil means still in production
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReceiptTemplate holds the printed receipt header/footer for a branch
+type ReceiptTemplate struct {
+	ID           uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Branch       string         `json:"branch" gorm:"size:50;not null;unique"`
+	WorkshopName string         `json:"workshop_name" gorm:"size:255;not null"`
+	Address      string         `json:"address" gorm:"size:255"`
+	Phone        string         `json:"phone" gorm:"size:50"`
+	Footer       string         `json:"footer" gorm:"size:500"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Permission names checked by the RequirePermission middleware
+const (
+	PermCategoryWrite  = "category.write"
+	PermProductWrite   = "product.write"
+	PermStockAdjust    = "stock.adjust"
+	PermSaleCreate     = "sale.create"
+	PermSaleVoid       = "sale.void"
+	PermReportSales    = "report.sales"
+	PermReportProfit   = "report.profit"
+	PermSettingsManage = "settings.manage"
+	PermUserManage     = "user.manage"
+	PermRoleManage     = "role.manage"
+)
+
+// AllPermissions is the catalogue of permissions a role can be granted
+var AllPermissions = []string{
+	PermCategoryWrite,
+	PermProductWrite,
+	PermStockAdjust,
+	PermSaleCreate,
+	PermSaleVoid,
+	PermReportSales,
+	PermReportProfit,
+	PermSettingsManage,
+	PermUserManage,
+	PermRoleManage,
+}
+
+// Built-in roles, which cannot be deleted
+const (
+	RoleAdmin    = "admin"
+	RoleKaryawan = "karyawan"
+)
+
+// Role is a named bundle of permissions assigned to users
+type Role struct {
+	ID               uint             `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name             string           `json:"name" gorm:"size:50;not null;unique"`
+	Description      string           `json:"description" gorm:"size:255"`
+	RequireTwoFactor bool             `json:"require_two_factor" gorm:"not null;default:false"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Permissions      []RolePermission `json:"permissions" gorm:"foreignKey:RoleID"`
+}
+
+// RolePermission grants one permission to a role
+type RolePermission struct {
+	ID         uint   `json:"-" gorm:"primaryKey;autoIncrement"`
+	RoleID     uint   `json:"-" gorm:"not null;uniqueIndex:idx_role_permissions_role_permission"`
+	Role       Role   `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Permission string `json:"permission" gorm:"size:50;not null;uniqueIndex:idx_role_permissions_role_permission"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Statuses of a scheduled price change
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusApplied   = "applied"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// ScheduledPriceChange is a future price that the price scheduler applies at EffectiveAt
+type ScheduledPriceChange struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID   uint       `json:"product_id" gorm:"not null;index"`
+	Product     Product    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	NewPrice    float64    `json:"new_price" gorm:"not null;check:new_price>=0"`
+	EffectiveAt time.Time  `json:"effective_at" gorm:"not null;index:idx_scheduled_price_changes_due"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:'pending';index:idx_scheduled_price_changes_due"`
+	Note        string     `json:"note" gorm:"size:255"`
+	CreatedByID uint       `json:"created_by_id" gorm:"not null"`
+	CreatedBy   User       `json:"created_by" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	AppliedAt   *time.Time `json:"applied_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
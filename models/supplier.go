@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier is a distributor the workshop buys products from
+type Supplier struct {
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string         `json:"name" gorm:"size:255;not null;unique"`
+	Phone     string         `json:"phone" gorm:"size:50"`
+	Address   string         `json:"address" gorm:"size:255"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
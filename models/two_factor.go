@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// UserRecoveryCode is a single-use code that replaces a TOTP code when the
+// authenticator device is lost
+type UserRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null;unique"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// LoginChallenge is issued after a correct password when a second factor is
+// still needed. It is exchanged for a session once the code is verified.
+type LoginChallenge struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;unique"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
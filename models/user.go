@@ -8,12 +8,17 @@ import (
 
 // User represents a system user (admin or karyawan)
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string         `json:"name" gorm:"size:255;not null"`
-	Email     string         `json:"email" gorm:"size:255;not null;unique"`
-	Password  string         `json:"password" gorm:"size:255;not null"`
-	Role      string         `json:"role" gorm:"type:enum('admin','karyawan');not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name              string         `json:"name" gorm:"size:255;not null"`
+	Email             string         `json:"email" gorm:"size:255;not null;unique"`
+	Password          string         `json:"-" gorm:"size:255;not null"`
+	Role              string         `json:"role" gorm:"size:50;not null;index"`
+	IsActive          bool           `json:"is_active" gorm:"not null;default:true"`
+	MustResetPassword bool           `json:"must_reset_password" gorm:"not null;default:false"`
+	TOTPEnabled       bool           `json:"totp_enabled" gorm:"not null;default:false"`
+	TOTPSecret        string         `json:"-" gorm:"size:64"`
+	TOTPLastStep      int64          `json:"-" gorm:"not null;default:0"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAuditLog records administrative changes made to a user account
+type UserAuditLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID      uint      `json:"actor_id" gorm:"not null;index"`
+	Actor        User      `json:"actor" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	TargetUserID uint      `json:"target_user_id" gorm:"not null;index"`
+	Action       string    `json:"action" gorm:"size:50;not null"`
+	Details      string    `json:"details" gorm:"size:500"`
+	CreatedAt    time.Time `json:"created_at"`
+}
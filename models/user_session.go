@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// UserSession is a login session. Access tokens carry the session ID so that
+// revoking the session invalidates them before they expire.
+type UserSession struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:32"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	User       User       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserAgent  string     `json:"user_agent" gorm:"size:255"`
+	IP         string     `json:"ip" gorm:"size:45"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RefreshToken is a single-use token that rotates on every refresh
+type RefreshToken struct {
+	ID        uint        `json:"id" gorm:"primaryKey;autoIncrement"`
+	SessionID string      `json:"session_id" gorm:"size:32;not null;index"`
+	Session   UserSession `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string      `json:"-" gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time   `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time  `json:"used_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Vehicle is a customer vehicle registered at the workshop
+type Vehicle struct {
+	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	PlateNumber string         `json:"plate_number" gorm:"size:20;not null;unique"`
+	OwnerName   string         `json:"owner_name" gorm:"size:255"`
+	Make        string         `json:"make" gorm:"size:100;not null"`
+	Model       string         `json:"model" gorm:"size:100;not null"`
+	Year        int            `json:"year" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
@@ -1,18 +1,25 @@
 package repository
 
 import (
+	"context"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"errors"
+	"fmt"
 )
 
 // ActivityRepository defines methods to interact with the products table.
 type ActivityRepository interface {
 	Create(product *models.Activity) error
+	CreateWithItems(activity *models.Activity, items []*models.ActivityItem) error
 	FindAll() ([]models.Activity, error)
 	FindByID(id uint) (*models.Activity, error)
 	Update(Activity *models.Activity) error
 	Delete(id uint) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ActivityRepository
 	// You can add other methods like FindByID, Update, Delete if needed
 }
 
@@ -28,6 +35,10 @@ func NewActivityRepository(db *gorm.DB) ActivityRepository {
 	}
 }
 
+func (r *ActivityRepositoryImpl) WithContext(ctx context.Context) ActivityRepository {
+	return &ActivityRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 
 func (r *ActivityRepositoryImpl) FindAll() ([]models.Activity, error) {
 	var activities []models.Activity
@@ -39,7 +50,7 @@ func (r *ActivityRepositoryImpl) FindAll() ([]models.Activity, error) {
 
 func (r *ActivityRepositoryImpl) FindByID(id uint) (*models.Activity, error) {
 	var activity models.Activity
-	err := r.DB.Preload("Items").Preload("User").First(&activity, id).Error
+	err := r.DB.Preload("Items.Product").Preload("User").First(&activity, id).Error
 	
     if errors.Is(err, gorm.ErrRecordNotFound) {
         // Return nil, nil to indicate not found without error
@@ -58,6 +69,110 @@ func (r *ActivityRepositoryImpl) Create(activity *models.Activity) error {
 	return r.DB.Create(activity).Error
 }
 
+// CreateWithItems stores an activity, its items, a stock transaction per item
+// and the resulting stock changes in one transaction, so a failure leaves
+// nothing behind. Outbound activities take the next invoice number of their
+// branch and year inside the same transaction, which keeps numbering gap-free.
+// Stock is changed with a guarded UPDATE, so concurrent sales cannot oversell:
+// a product without enough stock fails the whole activity with
+// insufficient_stock.
+func (r *ActivityRepositoryImpl) CreateWithItems(activity *models.Activity, items []*models.ActivityItem) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if activity.Type == "outbound" {
+			if err := assignInvoiceNumber(tx, activity); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(activity).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			item.ActivityID = activity.ID
+			if err := tx.Create(item).Error; err != nil {
+				return err
+			}
+
+			change := item.Quantity
+			if activity.Type == "outbound" {
+				change = -change
+			}
+			if err := tx.Create(&models.StockTransaction{
+				ProductID:      item.ProductID,
+				ChangeQuantity: change,
+				ActivityItemID: &item.ID,
+				Note:           "Stock change for activity",
+				Date:           activity.Date,
+				CreatedAt:      activity.CreatedAt,
+				UpdatedAt:      activity.UpdatedAt,
+			}).Error; err != nil {
+				return err
+			}
+
+			if err := changeStock(tx, item.ProductID, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// assignInvoiceNumber gives the activity the next number of its branch and
+// year, e.g. INV/default/2026/10/00042. The sequence row stays locked until
+// the transaction ends, so concurrent sales wait for each other.
+func assignInvoiceNumber(tx *gorm.DB, activity *models.Activity) error {
+	year := activity.Date.Year()
+
+	// Make sure the sequence row exists before locking it
+	seq := models.InvoiceSequence{Branch: activity.Branch, Year: year}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("branch = ? AND year = ?", activity.Branch, year).
+		First(&seq).Error; err != nil {
+		return err
+	}
+
+	seq.LastNumber++
+	if err := tx.Model(&seq).Update("last_number", seq.LastNumber).Error; err != nil {
+		return err
+	}
+
+	number := fmt.Sprintf("INV/%s/%04d/%02d/%05d", activity.Branch, year, int(activity.Date.Month()), seq.LastNumber)
+	activity.InvoiceNumber = &number
+	return nil
+}
+
+// changeStock adds change to the stock of a product in a single statement that
+// refuses to take the stock below zero
+func changeStock(tx *gorm.DB, productID uint, change int) error {
+	result := tx.Model(&models.Product{}).
+		Where("id = ? AND stock + ? >= 0", productID, change).
+		Update("stock", gorm.Expr("stock + ?", change))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	var product models.Product
+	if err := tx.First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("product_not_found", "product not found")
+		}
+		return err
+	}
+	return apperror.InsufficientStock(apperror.StockShortage{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Available:   product.Stock,
+		Requested:   -change,
+	})
+}
+
 func (r *ActivityRepositoryImpl) Update(activity *models.Activity) error {
 	return r.DB.Save(activity).Error
 }
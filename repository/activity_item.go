@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
 )
@@ -9,6 +10,8 @@ import (
 type ActivityItemRepository interface {
 	Create(product *models.ActivityItem) error
 	CreateMultiple(ActivityItems []*models.ActivityItem) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ActivityItemRepository
 }
 
 // ActivityItemRepositoryImpl is the implementation of the ActivityItemRepository interface.
@@ -23,6 +26,10 @@ func NewActivityItemRepository(db *gorm.DB) ActivityItemRepository {
 	}
 }
 
+func (r *ActivityItemRepositoryImpl) WithContext(ctx context.Context) ActivityItemRepository {
+	return &ActivityItemRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *ActivityItemRepositoryImpl) Create(ActivityItem *models.ActivityItem) error {
 	return r.DB.Create(ActivityItem).Error
 }
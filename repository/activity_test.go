@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestCreateWithItemsNumbersInvoicesPerBranch(t *testing.T) {
+	conn := dbtest.Open(t)
+	activities := repository.NewActivityRepository(conn)
+
+	user := createUser(t, conn, models.RoleAdmin)
+	product := createProduct(t, conn, createCategory(t, conn, "Oli"), "Oli Mesin", 10)
+
+	var numbers []string
+	for _, branch := range []string{"jkt-1", "jkt-1", "bdg"} {
+		sale := newActivity(user, "outbound")
+		sale.Branch = branch
+		if err := activities.CreateWithItems(sale, []*models.ActivityItem{item(product, 1)}); err != nil {
+			t.Fatalf("selling: %v", err)
+		}
+		numbers = append(numbers, *sale.InvoiceNumber)
+	}
+
+	want := []string{"INV/jkt-1/2026/10/00001", "INV/jkt-1/2026/10/00002", "INV/bdg/2026/10/00001"}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Errorf("invoice %d = %s, want %s", i, numbers[i], want[i])
+		}
+	}
+
+	restock := newActivity(user, "inbound")
+	if err := activities.CreateWithItems(restock, []*models.ActivityItem{item(product, 1)}); err != nil {
+		t.Fatalf("restocking: %v", err)
+	}
+	if restock.InvoiceNumber != nil {
+		t.Errorf("restock got invoice number %s", *restock.InvoiceNumber)
+	}
+}
+
+func TestCreateWithItemsLeavesNothingBehindOnInsufficientStock(t *testing.T) {
+	conn := dbtest.Open(t)
+	activities := repository.NewActivityRepository(conn)
+
+	user := createUser(t, conn, models.RoleAdmin)
+	category := createCategory(t, conn, "Oli")
+	oil := createProduct(t, conn, category, "Oli Mesin", 10)
+	tire := createProduct(t, conn, category, "Ban Dalam", 1)
+
+	err := activities.CreateWithItems(newActivity(user, "outbound"), []*models.ActivityItem{item(oil, 2), item(tire, 2)})
+	if !apperror.HasCode(err, "insufficient_stock") {
+		t.Fatalf("err = %v, want insufficient_stock", err)
+	}
+
+	var reloaded models.Product
+	conn.First(&reloaded, oil.ID)
+	if reloaded.Stock != 10 {
+		t.Errorf("oil stock = %d, want 10", reloaded.Stock)
+	}
+	for _, model := range []interface{}{&models.Activity{}, &models.ActivityItem{}, &models.StockTransaction{}, &models.InvoiceSequence{}} {
+		var count int64
+		conn.Model(model).Count(&count)
+		if count != 0 {
+			t.Errorf("%T: %d rows left behind", model, count)
+		}
+	}
+}
+
+func TestCreateWithItemsDoesNotOversell(t *testing.T) {
+	conn := dbtest.Open(t)
+	activities := repository.NewActivityRepository(conn)
+
+	user := createUser(t, conn, models.RoleAdmin)
+	product := createProduct(t, conn, createCategory(t, conn, "Oli"), "Oli Mesin", 10)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 6)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = activities.CreateWithItems(newActivity(user, "outbound"), []*models.ActivityItem{item(product, 2)})
+		}(i)
+	}
+	wg.Wait()
+
+	sold := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			sold++
+		case !apperror.HasCode(err, "insufficient_stock"):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if sold != 5 {
+		t.Errorf("%d sales went through, want 5", sold)
+	}
+
+	var reloaded models.Product
+	conn.First(&reloaded, product.ID)
+	if reloaded.Stock != 0 {
+		t.Errorf("stock = %d, want 0", reloaded.Stock)
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines methods to interact with the api_keys and api_key_scopes tables.
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindAll(page, limit int) ([]models.APIKey, int64, error)
+	FindByID(id uint) (*models.APIKey, error)
+	FindByHash(keyHash string) (*models.APIKey, error)
+	Revoke(id uint) error
+	TouchLastUsed(id uint, ip string) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) APIKeyRepository
+}
+
+// APIKeyRepositoryImpl is the implementation of the APIKeyRepository interface.
+type APIKeyRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepositoryImpl
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &APIKeyRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *APIKeyRepositoryImpl) WithContext(ctx context.Context) APIKeyRepository {
+	return &APIKeyRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *APIKeyRepositoryImpl) Create(key *models.APIKey) error {
+	return r.DB.Create(key).Error
+}
+
+func (r *APIKeyRepositoryImpl) FindAll(page, limit int) ([]models.APIKey, int64, error) {
+	var keys []models.APIKey
+	var total int64
+
+	if err := r.DB.Model(&models.APIKey{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := r.DB.Preload("Scopes").Preload("CreatedBy").Order("id DESC").Limit(limit).Offset(offset).Find(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
+
+func (r *APIKeyRepositoryImpl) FindByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.DB.Preload("Scopes").Preload("CreatedBy").First(&key, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepositoryImpl) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.DB.Preload("Scopes").Where("key_hash = ?", keyHash).First(&key).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepositoryImpl) Revoke(id uint) error {
+	return r.DB.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *APIKeyRepositoryImpl) TouchLastUsed(id uint, ip string) error {
+	return r.DB.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"last_used_ip": ip,
+	}).Error
+}
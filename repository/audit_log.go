@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows down the result of AuditLogRepository.FindAll.
+type AuditLogFilter struct {
+	Entity   string
+	EntityID string
+	Action   string
+	ActorID  *uint
+	From     *time.Time
+	To       *time.Time
+}
+
+// AuditLogRepository defines methods to interact with the audit_logs table.
+type AuditLogRepository interface {
+	Create(log *models.AuditLog) error
+	FindAll(filter AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) AuditLogRepository
+}
+
+// AuditLogRepositoryImpl is the implementation of the AuditLogRepository interface.
+type AuditLogRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewAuditLogRepository creates a new instance of AuditLogRepositoryImpl
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &AuditLogRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *AuditLogRepositoryImpl) WithContext(ctx context.Context) AuditLogRepository {
+	return &AuditLogRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *AuditLogRepositoryImpl) Create(log *models.AuditLog) error {
+	return r.DB.Create(log).Error
+}
+
+// FindAll fetches the audit logs matching the filter, newest first.
+func (r *AuditLogRepositoryImpl) FindAll(filter AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	if err := applyAuditLogFilter(r.DB.Model(&models.AuditLog{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := applyAuditLogFilter(r.DB, filter).
+		Preload("Actor").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+func applyAuditLogFilter(query *gorm.DB, filter AuditLogFilter) *gorm.DB {
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
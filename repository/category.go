@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
 	"errors"
@@ -13,7 +15,13 @@ type CategoryRepository interface {
 	FindByID(id uint) (*models.Category, error)
 	Update(category *models.Category) error
 	Delete(id uint) error
+	FindAllFlat() ([]models.Category, error)
+	CountProducts(id uint) (int64, error)
+	CountChildren(id uint) (int64, error)
+	SalesPerCategory() ([]models.CategorySales, error)
 	// You can add other methods like FindByID, Update, Delete if needed
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) CategoryRepository
 }
 
 // CategoryRepositoryImpl is the implementation of the CategoryRepository interface.
@@ -28,6 +36,10 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	}
 }
 
+func (r *CategoryRepositoryImpl) WithContext(ctx context.Context) CategoryRepository {
+	return &CategoryRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 // FindAll fetches all products from the database.
 func (r *CategoryRepositoryImpl) FindAll(page int, limit int) ([]models.Category, int64, error) {
 	
@@ -89,9 +101,60 @@ func (r *CategoryRepositoryImpl) Update(category *models.Category) error {
 // Delete removes a category from the database by its ID.
 func (r *CategoryRepositoryImpl) Delete(id uint) error {
 	var category models.Category
-	if err := r.DB.First(&category, id).Error; err != nil {
+	err := r.DB.First(&category, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound("category_not_found", "category not found")
+	}
+	if err != nil {
 		return err
 	}
 	return r.DB.Delete(&category).Error
 }
 
+// FindAllFlat fetches every category without products, for building the tree.
+func (r *CategoryRepositoryImpl) FindAllFlat() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.DB.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CountProducts counts the products directly in a category.
+func (r *CategoryRepositoryImpl) CountProducts(id uint) (int64, error) {
+	var total int64
+	err := r.DB.Model(&models.Product{}).Where("category_id = ?", id).Count(&total).Error
+	return total, err
+}
+
+// CountChildren counts the direct subcategories of a category.
+func (r *CategoryRepositoryImpl) CountChildren(id uint) (int64, error) {
+	var total int64
+	err := r.DB.Model(&models.Category{}).Where("parent_id = ?", id).Count(&total).Error
+	return total, err
+}
+
+// SalesPerCategory sums stock and outbound quantities of the products directly in each category.
+func (r *CategoryRepositoryImpl) SalesPerCategory() ([]models.CategorySales, error) {
+	var result []models.CategorySales
+
+	query := `
+			SELECT
+				c.id,
+				c.name,
+				COUNT(DISTINCT p.id) AS products,
+				COALESCE((SELECT SUM(p2.stock) FROM products p2 WHERE p2.category_id = c.id AND p2.deleted_at IS NULL), 0) AS stock,
+				COALESCE(SUM(CASE WHEN a.id IS NOT NULL THEN ai.quantity ELSE 0 END), 0) AS total_sales
+			FROM categories c
+			LEFT JOIN products p ON p.category_id = c.id AND p.deleted_at IS NULL
+			LEFT JOIN activity_items ai ON ai.product_id = p.id AND ai.deleted_at IS NULL
+			LEFT JOIN activities a ON ai.activity_id = a.id AND a.type = 'outbound' AND a.deleted_at IS NULL
+			WHERE c.deleted_at IS NULL
+			GROUP BY c.id, c.name
+	`
+
+	if err := r.DB.Raw(query).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,25 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestDeleteCategory(t *testing.T) {
+	conn := dbtest.Open(t)
+	categories := repository.NewCategoryRepository(conn)
+
+	category := createCategory(t, conn, "Oli")
+	if err := categories.Delete(category.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// Deleting it again, or an unknown category, is a 404
+	for _, id := range []uint{category.ID, 999} {
+		if err := categories.Delete(id); !apperror.HasCode(err, "category_not_found") {
+			t.Errorf("Delete(%d) = %v, want category_not_found", id, err)
+		}
+	}
+}
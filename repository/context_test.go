@@ -0,0 +1,25 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestWithContextRunsQueriesWithTheContext(t *testing.T) {
+	conn := dbtest.Open(t)
+	users := repository.NewUserRepository(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := users.WithContext(ctx).FindByID(1); !errors.Is(err, context.Canceled) {
+		t.Errorf("query on a cancelled context: %v, want context.Canceled", err)
+	}
+	if _, err := users.FindByID(1); err != nil {
+		t.Errorf("query on the original repository: %v", err)
+	}
+}
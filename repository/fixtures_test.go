@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+func createUser(t *testing.T, conn *gorm.DB, role string) *models.User {
+	t.Helper()
+	var count int64
+	conn.Model(&models.User{}).Count(&count)
+	user := &models.User{
+		Name:     "Budi",
+		Email:    fmt.Sprintf("user%d@example.com", count+1),
+		Password: "hash",
+		Role:     role,
+		IsActive: true,
+	}
+	if err := conn.Create(user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func createCategory(t *testing.T, conn *gorm.DB, name string) *models.Category {
+	t.Helper()
+	category := &models.Category{Name: name}
+	if err := conn.Create(category).Error; err != nil {
+		t.Fatalf("creating category: %v", err)
+	}
+	return category
+}
+
+func createProduct(t *testing.T, conn *gorm.DB, category *models.Category, name string, stock int) *models.Product {
+	t.Helper()
+	product := &models.Product{Name: name, Stock: stock, Price: 10000, Location: "Rak A", CategoryID: category.ID}
+	if err := conn.Create(product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	return product
+}
+
+func newActivity(user *models.User, activityType string) *models.Activity {
+	return &models.Activity{
+		UserID:        user.ID,
+		Branch:        "default",
+		PaymentStatus: "paid",
+		Date:          time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		Status:        "success",
+		Type:          activityType,
+	}
+}
+
+func item(product *models.Product, quantity int) *models.ActivityItem {
+	return &models.ActivityItem{
+		ProductID:   product.ID,
+		Quantity:    quantity,
+		PriceAtTime: product.Price,
+		FinalPrice:  product.Price,
+	}
+}
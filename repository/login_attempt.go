@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ThrottleKey identifies the login throttle of an account or an IP
+type ThrottleKey struct {
+	Kind       string
+	Identifier string
+}
+
+// LoginAttemptRepository defines methods to interact with the login_attempts and login_throttles tables.
+type LoginAttemptRepository interface {
+	Create(attempt *models.LoginAttempt) error
+	FindByUserID(userID uint, page, limit int) ([]models.LoginAttempt, int64, error)
+	UpdateThrottles(keys []ThrottleKey, update func(throttles []*models.LoginThrottle) error) error
+	ReleaseThrottle(kind, identifier string) error
+	DeleteThrottle(kind, identifier string) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) LoginAttemptRepository
+}
+
+// LoginAttemptRepositoryImpl is the implementation of the LoginAttemptRepository interface.
+type LoginAttemptRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewLoginAttemptRepository creates a new instance of LoginAttemptRepositoryImpl
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *LoginAttemptRepositoryImpl) WithContext(ctx context.Context) LoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *LoginAttemptRepositoryImpl) Create(attempt *models.LoginAttempt) error {
+	return r.DB.Create(attempt).Error
+}
+
+// FindByUserID fetches the login history of a user, newest first.
+func (r *LoginAttemptRepositoryImpl) FindByUserID(userID uint, page, limit int) ([]models.LoginAttempt, int64, error) {
+	var attempts []models.LoginAttempt
+	var total int64
+
+	query := r.DB.Model(&models.LoginAttempt{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&attempts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return attempts, total, nil
+}
+
+// UpdateThrottles locks the throttles of keys, creating the missing ones, and
+// passes them to update in the same order. The throttles are saved once update
+// returns; nothing is saved when it fails. Concurrent logins for the same
+// account or IP wait for each other, so none of them acts on stale counts.
+func (r *LoginAttemptRepositoryImpl) UpdateThrottles(keys []ThrottleKey, update func(throttles []*models.LoginThrottle) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		throttles := make([]*models.LoginThrottle, len(keys))
+		for i, key := range keys {
+			// Make sure the throttle exists before locking it
+			throttle := models.LoginThrottle{Kind: key.Kind, Identifier: key.Identifier}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&throttle).Error; err != nil {
+				return err
+			}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("kind = ? AND identifier = ?", key.Kind, key.Identifier).
+				First(&throttle).Error; err != nil {
+				return err
+			}
+			throttles[i] = &throttle
+		}
+
+		if err := update(throttles); err != nil {
+			return err
+		}
+
+		for _, throttle := range throttles {
+			if err := tx.Save(throttle).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseThrottle takes back one failure counted against a throttle in a
+// single statement
+func (r *LoginAttemptRepositoryImpl) ReleaseThrottle(kind, identifier string) error {
+	return r.DB.Model(&models.LoginThrottle{}).
+		Where("kind = ? AND identifier = ? AND failures > 0", kind, identifier).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+func (r *LoginAttemptRepositoryImpl) DeleteThrottle(kind, identifier string) error {
+	return r.DB.Where("kind = ? AND identifier = ?", kind, identifier).Delete(&models.LoginThrottle{}).Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokenRepository defines methods to interact with the password_reset_tokens table.
+type PasswordResetTokenRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint) error
+	InvalidateForUser(userID uint) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) PasswordResetTokenRepository
+}
+
+// PasswordResetTokenRepositoryImpl is the implementation of the PasswordResetTokenRepository interface.
+type PasswordResetTokenRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewPasswordResetTokenRepository creates a new instance of PasswordResetTokenRepositoryImpl
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &PasswordResetTokenRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *PasswordResetTokenRepositoryImpl) WithContext(ctx context.Context) PasswordResetTokenRepository {
+	return &PasswordResetTokenRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *PasswordResetTokenRepositoryImpl) Create(token *models.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *PasswordResetTokenRepositoryImpl) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a token. It fails when the token was already used, so a
+// token cannot be redeemed twice by concurrent requests.
+func (r *PasswordResetTokenRepositoryImpl) MarkUsed(id uint) error {
+	res := r.DB.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.Conflict("reset_token_used", "reset token already used")
+	}
+	return nil
+}
+
+// InvalidateForUser consumes every pending token of a user.
+func (r *PasswordResetTokenRepositoryImpl) InvalidateForUser(userID uint) error {
+	return r.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
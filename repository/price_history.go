@@ -1,13 +1,17 @@
 package repository
 
 import (
+	"context"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
 )
 
-// PriceHistoryRepository defines methods to interact with the products table.
+// PriceHistoryRepository defines methods to interact with the price_histories table.
 type PriceHistoryRepository interface {
 	Create(product *models.PriceHistory) error
+	FindByProductID(productID uint, page, limit int) ([]models.PriceHistory, int64, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) PriceHistoryRepository
 }
 
 // PriceHistoryRepositoryImpl is the implementation of the PriceHistoryRepository interface.
@@ -22,7 +26,33 @@ func NewPriceHistoryRepository(db *gorm.DB) PriceHistoryRepository {
 	}
 }
 
+func (r *PriceHistoryRepositoryImpl) WithContext(ctx context.Context) PriceHistoryRepository {
+	return &PriceHistoryRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 // Create adds a new product to the database.
 func (r *PriceHistoryRepositoryImpl) Create(product *models.PriceHistory) error {
 	return r.DB.Create(product).Error
 }
+
+// FindByProductID fetches the price changes of a product, newest first.
+func (r *PriceHistoryRepositoryImpl) FindByProductID(productID uint, page, limit int) ([]models.PriceHistory, int64, error) {
+	var history []models.PriceHistory
+	var total int64
+
+	query := r.DB.Model(&models.PriceHistory{}).Where("product_id = ?", productID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Preload("ChangedBy").
+		Order("date_changed DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&history).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return history, total, nil
+}
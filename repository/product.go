@@ -1,21 +1,31 @@
 package repository
 
 import (
+	"context"
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
-	"fmt"
 	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 // ProductRepository defines methods to interact with the products table.
 type ProductRepository interface {
 	Create(product *models.Product) error
-	FindAll(page int, limit int) ([]models.Product, int64, error)
+	FindAll(filter ProductFilter, page int, limit int) ([]models.Product, int64, error)
+	FindBySKU(sku string) (*models.Product, error)
 	FindByID(id uint) (*models.Product, error)
 	FindByIDs(ids []uint) ([]models.Product, error)
 	Update(product *models.Product) error
 	Delete(id uint) error
 	FindAllWithSales(page int, limit int) ([]models.ProductSales, int64, error)
+	FindForPricing(selector PriceSelector) ([]models.Product, error)
+	UpdatePrices(selector PriceSelector, actorID uint, reprice func(models.Product) (float64, error)) ([]models.PriceHistory, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ProductRepository
 }
 
 // ProductRepositoryImpl is the implementation of the ProductRepository interface.
@@ -30,32 +40,58 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	}
 }
 
+func (r *ProductRepositoryImpl) WithContext(ctx context.Context) ProductRepository {
+	return &ProductRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 // Create adds a new product to the database.
 func (r *ProductRepositoryImpl) Create(product *models.Product) error {
 	return r.DB.Create(product).Error
 }
 
-// FindAll fetches all products from the database.
-func (r *ProductRepositoryImpl) FindAll(page int, limit int) ([]models.Product, int64, error) {
+// ProductFilter narrows down and orders the result of FindAll.
+type ProductFilter struct {
+	Search            string
+	CategoryIDs       []uint
+	Location          string
+	MinStock          *int
+	MaxStock          *int
+	MinPrice          *float64
+	MaxPrice          *float64
+	LowStock          bool
+	LowStockThreshold int
+	Sort              string // name, price, stock or last_sold
+	Desc              bool
+}
+
+// lastSoldQuery returns the date a product was last sold.
+const lastSoldQuery = `(SELECT MAX(a.date) FROM activity_items ai
+	JOIN activities a ON a.id = ai.activity_id
+	WHERE ai.product_id = products.id AND a.type = 'outbound' AND a.deleted_at IS NULL AND ai.deleted_at IS NULL)`
+
+// FindAll fetches all products matching the filter from the database.
+func (r *ProductRepositoryImpl) FindAll(filter ProductFilter, page int, limit int) ([]models.Product, int64, error) {
 	var products []models.Product
 	var total int64
 
-	// Count total products
-	err := r.DB.Model(&models.Product{}).Count(&total).Error
+	// Count total products matching the filter
+	err := applyProductFilter(r.DB.Model(&models.Product{}), filter).Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
+	query := applyProductSort(applyProductFilter(r.DB, filter), filter)
+
 	if page > 0 && limit > 0 {
 		offset := (page - 1) * limit
-		if err := r.DB.Preload("Category").
+		if err := query.Preload("Category").Preload("Supplier").
 			Limit(limit).
 			Offset(offset).
 			Find(&products).Error; err != nil {
 			return nil, 0, err
 		}
 	} else {
-		if err := r.DB.Preload("Category").Find(&products).Error; err != nil {
+		if err := query.Preload("Category").Preload("Supplier").Find(&products).Error; err != nil {
 			return nil, 0, err
 		}
 	}
@@ -63,9 +99,95 @@ func (r *ProductRepositoryImpl) FindAll(page int, limit int) ([]models.Product,
 	return products, total, nil
 }
 
+func applyProductFilter(query *gorm.DB, filter ProductFilter) *gorm.DB {
+	for _, term := range strings.Fields(strings.ToLower(filter.Search)) {
+		conds := []string{}
+		args := []interface{}{}
+		for _, pattern := range searchPatterns(term) {
+			conds = append(conds, "LOWER(products.name) LIKE ? ESCAPE '!' OR LOWER(products.sku) LIKE ? ESCAPE '!' OR LOWER(products.brand) LIKE ? ESCAPE '!'")
+			args = append(args, pattern, pattern, pattern)
+		}
+		query = query.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("products.category_id IN ?", filter.CategoryIDs)
+	}
+	if filter.Location != "" {
+		query = query.Where("LOWER(products.location) LIKE ? ESCAPE '!'", "%"+escapeLike(strings.ToLower(filter.Location))+"%")
+	}
+	if filter.MinStock != nil {
+		query = query.Where("products.stock >= ?", *filter.MinStock)
+	}
+	if filter.MaxStock != nil {
+		query = query.Where("products.stock <= ?", *filter.MaxStock)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("products.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	}
+	if filter.LowStock {
+		query = query.Where("products.stock <= ?", filter.LowStockThreshold)
+	}
+
+	return query
+}
+
+func applyProductSort(query *gorm.DB, filter ProductFilter) *gorm.DB {
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+
+	switch filter.Sort {
+	case "name":
+		query = query.Order("products.name " + direction)
+	case "price":
+		query = query.Order("products.price " + direction)
+	case "stock":
+		query = query.Order("products.stock " + direction)
+	case "last_sold":
+		query = query.Order(lastSoldQuery + " " + direction)
+	}
+
+	// Keep pagination stable
+	return query.Order("products.id " + direction)
+}
+
+// searchPatterns builds LIKE patterns for a search term. Besides the exact
+// substring, terms of 4+ characters also match with one mistyped character
+// (every position replaced by "_") or one missing character (a "_" inserted
+// between every pair of characters).
+func searchPatterns(term string) []string {
+	runes := []rune(escapeLike(term))
+	patterns := []string{"%" + string(runes) + "%"}
+	if len([]rune(term)) < 4 || len(runes) != len([]rune(term)) {
+		return patterns
+	}
+
+	for i := range runes {
+		typo := append([]rune{}, runes...)
+		typo[i] = '_'
+		patterns = append(patterns, "%"+string(typo)+"%")
+	}
+	for i := 1; i < len(runes); i++ {
+		missing := string(runes[:i]) + "_" + string(runes[i:])
+		patterns = append(patterns, "%"+missing+"%")
+	}
+	return patterns
+}
+
+// escapeLike escapes the LIKE wildcards in s. "!" is used as the escape
+// character because the backslash is treated differently by every database.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
 func (r *ProductRepositoryImpl) FindByID(id uint) (*models.Product, error) {
     var product models.Product
-    err := r.DB.Preload("Category").First(&product, id).Error
+    err := r.DB.Preload("Category").Preload("Supplier").First(&product, id).Error
 
     if errors.Is(err, gorm.ErrRecordNotFound) {
         // Return nil, nil to indicate not found without error
@@ -80,13 +202,33 @@ func (r *ProductRepositoryImpl) FindByID(id uint) (*models.Product, error) {
     return &product, nil
 }
 
+// FindBySKU fetches a product by its SKU.
+func (r *ProductRepositoryImpl) FindBySKU(sku string) (*models.Product, error) {
+	var product models.Product
+	err := r.DB.Where("sku = ?", sku).First(&product).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
 func (r *ProductRepositoryImpl) Update(product *models.Product) error {
 	return r.DB.Save(product).Error
 }
 
 func (r *ProductRepositoryImpl) Delete(id uint) error {
 	var product models.Product
-	if err := r.DB.First(&product, id).Error; err != nil {
+	err := r.DB.First(&product, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound("product_not_found", "product not found")
+	}
+	if err != nil {
 		return err
 	}
 	return r.DB.Delete(&product).Error
@@ -110,31 +252,102 @@ func (r *ProductRepositoryImpl) FindAllWithSales(page int, limit int) ([]models.
 		return nil, 0, err
 	}
 
-	query := `
-			SELECT 
-				p.id, 
-				p.name, 
-				p.stock,
-				c.name AS category,
-				ABS(COALESCE(SUM(ai.quantity), 0)) AS total_sales
-			FROM products p
-			JOIN categories c ON p.category_id = c.id
-			LEFT JOIN activity_items ai ON ai.product_id = p.id
-			LEFT JOIN activities a ON ai.activity_id = a.id AND a.type = 'outbound'
-			WHERE p.deleted_at IS NULL
-			GROUP BY p.id, p.name, p.stock, c.name
-			ORDER BY total_sales DESC
-	`
-
-	
+	query := r.DB.Table("products p").
+		// Items of inbound activities join with a NULL activity and are not counted
+		Select("p.id, p.name, p.stock, c.name AS category, COALESCE(SUM(CASE WHEN a.id IS NOT NULL THEN ai.quantity ELSE 0 END), 0) AS total_sales").
+		Joins("JOIN categories c ON p.category_id = c.id").
+		Joins("LEFT JOIN activity_items ai ON ai.product_id = p.id AND ai.deleted_at IS NULL").
+		Joins("LEFT JOIN activities a ON ai.activity_id = a.id AND a.type = ? AND a.deleted_at IS NULL", "outbound").
+		Where("p.deleted_at IS NULL").
+		Group("p.id, p.name, p.stock, c.name").
+		Order("total_sales DESC")
+
 	if page > 0 && limit > 0 {
-		offset := (page - 1) * limit
-		query += fmt.Sprintf("	LIMIT %d OFFSET %d", limit, offset)
+		query = query.Limit(limit).Offset((page - 1) * limit)
 	}
-	
-	if err := r.DB.Raw(query).Scan(&result).Error; err != nil {
+
+	if err := query.Scan(&result).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return result, total, nil
-}
\ No newline at end of file
+}
+
+// PriceSelector picks the products of a bulk price change. Every non-empty
+// field narrows the selection further.
+type PriceSelector struct {
+	CategoryIDs []uint
+	SupplierID  uint
+	IDs         []uint
+}
+
+func applyPriceSelector(query *gorm.DB, selector PriceSelector) *gorm.DB {
+	if len(selector.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", selector.CategoryIDs)
+	}
+	if selector.SupplierID > 0 {
+		query = query.Where("supplier_id = ?", selector.SupplierID)
+	}
+	if len(selector.IDs) > 0 {
+		query = query.Where("id IN ?", selector.IDs)
+	}
+	return query.Order("id")
+}
+
+// FindForPricing fetches the products matched by a price selector.
+func (r *ProductRepositoryImpl) FindForPricing(selector PriceSelector) ([]models.Product, error) {
+	var products []models.Product
+	if err := applyPriceSelector(r.DB, selector).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpdatePrices reprices every selected product in a single transaction and
+// logs one price history entry per changed product. The products are locked
+// while repricing, so the new prices are computed from the current ones.
+// Nothing is saved when reprice fails for any product.
+func (r *ProductRepositoryImpl) UpdatePrices(selector PriceSelector, actorID uint, reprice func(models.Product) (float64, error)) ([]models.PriceHistory, error) {
+	var history []models.PriceHistory
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var products []models.Product
+		if err := applyPriceSelector(tx.Clauses(clause.Locking{Strength: "UPDATE"}), selector).Find(&products).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, p := range products {
+			newPrice, err := reprice(p)
+			if err != nil {
+				return err
+			}
+			if newPrice == p.Price {
+				continue
+			}
+
+			entry := models.PriceHistory{
+				ProductID:   p.ID,
+				OldPrice:    p.Price,
+				NewPrice:    newPrice,
+				DateChanged: now,
+				ChangedByID: &actorID,
+				Source:      models.PriceSourceBulk,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Product{}).Where("id = ?", p.ID).
+				Updates(map[string]interface{}{"price": newPrice, "updated_at": now}).Error; err != nil {
+				return err
+			}
+			history = append(history, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// ProductFitmentRepository defines methods to interact with the product_fitments table.
+type ProductFitmentRepository interface {
+	Create(fitment *models.ProductFitment) error
+	CreateMultiple(fitments []*models.ProductFitment) error
+	FindByProductID(productID uint) ([]models.ProductFitment, error)
+	FindCompatibleProducts(vehicleMake, vehicleModel string, year int, page int, limit int) ([]models.Product, int64, error)
+	Delete(id uint) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ProductFitmentRepository
+}
+
+// ProductFitmentRepositoryImpl is the implementation of the ProductFitmentRepository interface.
+type ProductFitmentRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewProductFitmentRepository creates a new instance of ProductFitmentRepositoryImpl
+func NewProductFitmentRepository(db *gorm.DB) ProductFitmentRepository {
+	return &ProductFitmentRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *ProductFitmentRepositoryImpl) WithContext(ctx context.Context) ProductFitmentRepository {
+	return &ProductFitmentRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *ProductFitmentRepositoryImpl) Create(fitment *models.ProductFitment) error {
+	return r.DB.Create(fitment).Error
+}
+
+// CreateMultiple inserts all fitments in a single transaction.
+func (r *ProductFitmentRepositoryImpl) CreateMultiple(fitments []*models.ProductFitment) error {
+	if len(fitments) == 0 {
+		return nil // No items to create
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(fitments, 500).Error
+	})
+}
+
+func (r *ProductFitmentRepositoryImpl) FindByProductID(productID uint) ([]models.ProductFitment, error) {
+	var fitments []models.ProductFitment
+	if err := r.DB.Where("product_id = ?", productID).Order("make, model, year_from").Find(&fitments).Error; err != nil {
+		return nil, err
+	}
+	return fitments, nil
+}
+
+// FindCompatibleProducts fetches the products fitting a vehicle make, model and year.
+func (r *ProductFitmentRepositoryImpl) FindCompatibleProducts(vehicleMake, vehicleModel string, year int, page int, limit int) ([]models.Product, int64, error) {
+	var products []models.Product
+	var total int64
+
+	fits := r.DB.Model(&models.ProductFitment{}).
+		Select("product_id").
+		Where("LOWER(make) = LOWER(?) AND LOWER(model) = LOWER(?)", vehicleMake, vehicleModel).
+		Where("year_from <= ? AND (year_to IS NULL OR year_to >= ?)", year, year)
+
+	err := r.DB.Model(&models.Product{}).Where("id IN (?)", fits).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.DB.Preload("Category").Where("id IN (?)", fits).Order("name")
+	if page > 0 && limit > 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	if err := query.Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+func (r *ProductFitmentRepositoryImpl) Delete(id uint) error {
+	var fitment models.ProductFitment
+	err := r.DB.First(&fitment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound("fitment_not_found", "fitment not found")
+	}
+	if err != nil {
+		return err
+	}
+	return r.DB.Delete(&fitment).Error
+}
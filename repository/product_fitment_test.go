@@ -0,0 +1,31 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestDeleteFitment(t *testing.T) {
+	conn := dbtest.Open(t)
+	fitments := repository.NewProductFitmentRepository(conn)
+
+	product := createProduct(t, conn, createCategory(t, conn, "Oli"), "Oli Mesin", 10)
+	fitment := &models.ProductFitment{ProductID: product.ID, Make: "Honda", Model: "Beat", YearFrom: 2015}
+	if err := fitments.Create(fitment); err != nil {
+		t.Fatalf("creating fitment: %v", err)
+	}
+
+	if err := fitments.Delete(fitment.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// Deleting it again, or an unknown fitment, is a 404
+	for _, id := range []uint{fitment.ID, 999} {
+		if err := fitments.Delete(id); !apperror.HasCode(err, "fitment_not_found") {
+			t.Errorf("Delete(%d) = %v, want fitment_not_found", id, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestFindAllWithSalesCountsOnlySales(t *testing.T) {
+	conn := dbtest.Open(t)
+	activities := repository.NewActivityRepository(conn)
+	products := repository.NewProductRepository(conn)
+
+	user := createUser(t, conn, models.RoleAdmin)
+	category := createCategory(t, conn, "Oli")
+	oil := createProduct(t, conn, category, "Oli Mesin", 10)
+	tire := createProduct(t, conn, category, "Ban Dalam", 10)
+
+	if err := activities.CreateWithItems(newActivity(user, "inbound"), []*models.ActivityItem{item(oil, 20), item(tire, 5)}); err != nil {
+		t.Fatalf("restocking: %v", err)
+	}
+	if err := activities.CreateWithItems(newActivity(user, "outbound"), []*models.ActivityItem{item(oil, 3)}); err != nil {
+		t.Fatalf("selling: %v", err)
+	}
+	if err := activities.CreateWithItems(newActivity(user, "outbound"), []*models.ActivityItem{item(oil, 4)}); err != nil {
+		t.Fatalf("selling: %v", err)
+	}
+
+	result, total, err := products.FindAllWithSales(1, 10)
+	if err != nil {
+		t.Fatalf("FindAllWithSales: %v", err)
+	}
+	if total != 2 || len(result) != 2 {
+		t.Fatalf("got %d of %d products, want 2 of 2", len(result), total)
+	}
+
+	sales := map[uint]models.ProductSales{}
+	for _, r := range result {
+		sales[r.ID] = r
+	}
+	if got := sales[oil.ID]; got.TotalSales != 7 || got.Stock != 23 || got.Category != "Oli" {
+		t.Errorf("oil = %+v, want 7 sold and 23 in stock", got)
+	}
+	if got := sales[tire.ID]; got.TotalSales != 0 || got.Stock != 15 {
+		t.Errorf("tire = %+v, want 0 sold and 15 in stock", got)
+	}
+	if result[0].ID != oil.ID {
+		t.Errorf("best seller = %d, want %d", result[0].ID, oil.ID)
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// ReceiptTemplateRepository defines methods to interact with the receipt_templates table.
+type ReceiptTemplateRepository interface {
+	FindByBranch(branch string) (*models.ReceiptTemplate, error)
+	Save(template *models.ReceiptTemplate) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ReceiptTemplateRepository
+}
+
+// ReceiptTemplateRepositoryImpl is the implementation of the ReceiptTemplateRepository interface.
+type ReceiptTemplateRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewReceiptTemplateRepository creates a new instance of ReceiptTemplateRepositoryImpl
+func NewReceiptTemplateRepository(db *gorm.DB) ReceiptTemplateRepository {
+	return &ReceiptTemplateRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *ReceiptTemplateRepositoryImpl) WithContext(ctx context.Context) ReceiptTemplateRepository {
+	return &ReceiptTemplateRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+// FindByBranch fetches the receipt template of a branch.
+func (r *ReceiptTemplateRepositoryImpl) FindByBranch(branch string) (*models.ReceiptTemplate, error) {
+	var template models.ReceiptTemplate
+	err := r.DB.Where("branch = ?", branch).First(&template).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Return nil, nil to indicate not found without error
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// Save creates or updates a receipt template.
+func (r *ReceiptTemplateRepositoryImpl) Save(template *models.ReceiptTemplate) error {
+	return r.DB.Save(template).Error
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// RoleRepository defines methods to interact with the roles and role_permissions tables.
+type RoleRepository interface {
+	FindAll() ([]models.Role, error)
+	FindByName(name string) (*models.Role, error)
+	Create(role *models.Role) error
+	ReplacePermissions(role *models.Role, permissions []string) error
+	Delete(role *models.Role) error
+	CountUsers(name string) (int64, error)
+	SetRequireTwoFactor(role *models.Role, required bool) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) RoleRepository
+}
+
+// RoleRepositoryImpl is the implementation of the RoleRepository interface.
+type RoleRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewRoleRepository creates a new instance of RoleRepositoryImpl
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &RoleRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *RoleRepositoryImpl) WithContext(ctx context.Context) RoleRepository {
+	return &RoleRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *RoleRepositoryImpl) FindAll() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.DB.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepositoryImpl) Create(role *models.Role) error {
+	return r.DB.Create(role).Error
+}
+
+// ReplacePermissions swaps the whole permission set of a role in one transaction.
+func (r *RoleRepositoryImpl) ReplacePermissions(role *models.Role, permissions []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+
+		role.Permissions = nil
+		for _, p := range permissions {
+			role.Permissions = append(role.Permissions, models.RolePermission{RoleID: role.ID, Permission: p})
+		}
+		if len(role.Permissions) > 0 {
+			if err := tx.Create(&role.Permissions).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(role).Update("updated_at", time.Now()).Error
+	})
+}
+
+func (r *RoleRepositoryImpl) Delete(role *models.Role) error {
+	return r.DB.Select("Permissions").Delete(role).Error
+}
+
+// CountUsers counts the users holding a role.
+func (r *RoleRepositoryImpl) CountUsers(name string) (int64, error) {
+	var total int64
+	err := r.DB.Model(&models.User{}).Where("role = ?", name).Count(&total).Error
+	return total, err
+}
+
+// SetRequireTwoFactor turns the two-factor policy of a role on or off.
+func (r *RoleRepositoryImpl) SetRequireTwoFactor(role *models.Role, required bool) error {
+	role.RequireTwoFactor = required
+	return r.DB.Model(role).Updates(map[string]interface{}{
+		"require_two_factor": required,
+		"updated_at":         time.Now(),
+	}).Error
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScheduledPriceChangeRepository defines methods to interact with the scheduled_price_changes table.
+type ScheduledPriceChangeRepository interface {
+	Create(change *models.ScheduledPriceChange) error
+	FindByID(id uint) (*models.ScheduledPriceChange, error)
+	FindByProductID(productID uint, status string) ([]models.ScheduledPriceChange, error)
+	FindDue(now time.Time, limit int) ([]models.ScheduledPriceChange, error)
+	Cancel(id uint) error
+	Apply(change *models.ScheduledPriceChange) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) ScheduledPriceChangeRepository
+}
+
+// ScheduledPriceChangeRepositoryImpl is the implementation of the ScheduledPriceChangeRepository interface.
+type ScheduledPriceChangeRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewScheduledPriceChangeRepository creates a new instance of ScheduledPriceChangeRepositoryImpl
+func NewScheduledPriceChangeRepository(db *gorm.DB) ScheduledPriceChangeRepository {
+	return &ScheduledPriceChangeRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *ScheduledPriceChangeRepositoryImpl) WithContext(ctx context.Context) ScheduledPriceChangeRepository {
+	return &ScheduledPriceChangeRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *ScheduledPriceChangeRepositoryImpl) Create(change *models.ScheduledPriceChange) error {
+	return r.DB.Create(change).Error
+}
+
+func (r *ScheduledPriceChangeRepositoryImpl) FindByID(id uint) (*models.ScheduledPriceChange, error) {
+	var change models.ScheduledPriceChange
+	err := r.DB.Preload("CreatedBy").First(&change, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// FindByProductID fetches the scheduled changes of a product in effective order.
+// An empty status returns all of them.
+func (r *ScheduledPriceChangeRepositoryImpl) FindByProductID(productID uint, status string) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	query := r.DB.Preload("CreatedBy").Where("product_id = ?", productID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("effective_at, id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// FindDue fetches pending changes whose effective time has passed, oldest first.
+func (r *ScheduledPriceChangeRepositoryImpl) FindDue(now time.Time, limit int) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	if err := r.DB.Where("status = ? AND effective_at <= ?", models.ScheduleStatusPending, now).
+		Order("effective_at, id").
+		Limit(limit).
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Cancel withdraws a pending change. It fails when the change is no longer pending.
+func (r *ScheduledPriceChangeRepositoryImpl) Cancel(id uint) error {
+	res := r.DB.Model(&models.ScheduledPriceChange{}).
+		Where("id = ? AND status = ?", id, models.ScheduleStatusPending).
+		Updates(map[string]interface{}{"status": models.ScheduleStatusCancelled, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.Conflict("scheduled_price_change_not_pending", "scheduled price change is not pending")
+	}
+	return nil
+}
+
+// Apply sets the product price, logs it in the price history and marks the
+// change applied, all in one transaction. Claiming the change with a
+// conditional update first makes it safe when several instances run the
+// scheduler at once.
+func (r *ScheduledPriceChangeRepositoryImpl) Apply(change *models.ScheduledPriceChange) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.ScheduledPriceChange{}).
+			Where("id = ? AND status = ?", change.ID, models.ScheduleStatusPending).
+			Updates(map[string]interface{}{"status": models.ScheduleStatusApplied, "applied_at": now, "updated_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.Conflict("scheduled_price_change_not_pending", "scheduled price change is not pending")
+		}
+
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, change.ProductID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("product_not_found", "product not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if product.Price != change.NewPrice {
+			history := models.PriceHistory{
+				ProductID:   product.ID,
+				OldPrice:    product.Price,
+				NewPrice:    change.NewPrice,
+				DateChanged: now,
+				ChangedByID: &change.CreatedByID,
+				Source:      models.PriceSourceScheduled,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Model(&product).Updates(map[string]interface{}{"price": change.NewPrice, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		}
+
+		change.Status = models.ScheduleStatusApplied
+		change.AppliedAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository defines methods to interact with the user_sessions and refresh_tokens tables.
+type SessionRepository interface {
+	Create(session *models.UserSession, token *models.RefreshToken) error
+	FindByID(id string) (*models.UserSession, error)
+	FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	Rotate(used *models.RefreshToken, next *models.RefreshToken) error
+	Revoke(id string) error
+	RevokeAllForUser(userID uint) error
+	RevokeOthersForUser(userID uint, keepID string) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) SessionRepository
+}
+
+// SessionRepositoryImpl is the implementation of the SessionRepository interface.
+type SessionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepositoryImpl
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &SessionRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *SessionRepositoryImpl) WithContext(ctx context.Context) SessionRepository {
+	return &SessionRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+// Create stores a new session with its first refresh token.
+func (r *SessionRepositoryImpl) Create(session *models.UserSession, token *models.RefreshToken) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+func (r *SessionRepositoryImpl) FindByID(id string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.DB.Where("id = ?", id).First(&session).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindRefreshToken fetches a refresh token with its session by the token hash.
+func (r *SessionRepositoryImpl) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.DB.Preload("Session").Where("token_hash = ?", tokenHash).First(&token).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Rotate marks a refresh token as used and stores its successor. The update is
+// conditional so two concurrent refreshes with the same token cannot both win.
+func (r *SessionRepositoryImpl) Rotate(used *models.RefreshToken, next *models.RefreshToken) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", used.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.Conflict("refresh_token_used", "refresh token already used")
+		}
+
+		if err := tx.Model(&models.UserSession{}).Where("id = ?", used.SessionID).Update("last_used_at", now).Error; err != nil {
+			return err
+		}
+
+		next.SessionID = used.SessionID
+		return tx.Create(next).Error
+	})
+}
+
+func (r *SessionRepositoryImpl) Revoke(id string) error {
+	return r.DB.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *SessionRepositoryImpl) RevokeAllForUser(userID uint) error {
+	return r.DB.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOthersForUser revokes every session of a user except keepID.
+func (r *SessionRepositoryImpl) RevokeOthersForUser(userID uint, keepID string) error {
+	return r.DB.Model(&models.UserSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
 )
@@ -9,6 +10,8 @@ import (
 type StockTransactionRepository interface {
 	Create(product *models.StockTransaction) error
 	CreateMultiple(StockTransactions []*models.StockTransaction) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) StockTransactionRepository
 }
 
 // StockTransactionRepositoryImpl is the implementation of the StockTransactionRepository interface.
@@ -23,6 +26,10 @@ func NewStockTransactionRepository(db *gorm.DB) StockTransactionRepository {
 	}
 }
 
+func (r *StockTransactionRepositoryImpl) WithContext(ctx context.Context) StockTransactionRepository {
+	return &StockTransactionRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *StockTransactionRepositoryImpl) Create(StockTransaction *models.StockTransaction) error {
 	return r.DB.Create(StockTransaction).Error
 }
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// SupplierRepository defines methods to interact with the suppliers table.
+type SupplierRepository interface {
+	Create(supplier *models.Supplier) error
+	FindAll(page int, limit int) ([]models.Supplier, int64, error)
+	FindByID(id uint) (*models.Supplier, error)
+	FindByName(name string) (*models.Supplier, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) SupplierRepository
+}
+
+// SupplierRepositoryImpl is the implementation of the SupplierRepository interface.
+type SupplierRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewSupplierRepository creates a new instance of SupplierRepositoryImpl
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &SupplierRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *SupplierRepositoryImpl) WithContext(ctx context.Context) SupplierRepository {
+	return &SupplierRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *SupplierRepositoryImpl) Create(supplier *models.Supplier) error {
+	return r.DB.Create(supplier).Error
+}
+
+func (r *SupplierRepositoryImpl) FindAll(page int, limit int) ([]models.Supplier, int64, error) {
+	var suppliers []models.Supplier
+	var total int64
+
+	if err := r.DB.Model(&models.Supplier{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.DB.Order("name")
+	if page > 0 && limit > 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	if err := query.Find(&suppliers).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return suppliers, total, nil
+}
+
+func (r *SupplierRepositoryImpl) FindByID(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.DB.First(&supplier, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &supplier, nil
+}
+
+// FindByName looks a supplier up by name, ignoring case
+func (r *SupplierRepositoryImpl) FindByName(name string) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.DB.Where("LOWER(name) = LOWER(?)", name).First(&supplier).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &supplier, nil
+}
@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+func TestPriceSelectorPicksTheSuppliersProducts(t *testing.T) {
+	conn := dbtest.Open(t)
+	products := repository.NewProductRepository(conn)
+	suppliers := repository.NewSupplierRepository(conn)
+
+	supplier := &models.Supplier{Name: "PT Sumber Oli"}
+	if err := suppliers.Create(supplier); err != nil {
+		t.Fatalf("creating supplier: %v", err)
+	}
+	category := createCategory(t, conn, "Oli")
+	supplied := createProduct(t, conn, category, "Oli Mesin", 10)
+	other := createProduct(t, conn, category, "Oli Gardan", 10)
+	if err := conn.Model(supplied).Update("supplier_id", supplier.ID).Error; err != nil {
+		t.Fatalf("setting supplier: %v", err)
+	}
+
+	history, err := products.UpdatePrices(repository.PriceSelector{SupplierID: supplier.ID}, createUser(t, conn, models.RoleAdmin).ID, func(p models.Product) (float64, error) {
+		return p.Price * 1.1, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdatePrices: %v", err)
+	}
+	if len(history) != 1 || history[0].ProductID != supplied.ID {
+		t.Fatalf("repriced %+v, want only product %d", history, supplied.ID)
+	}
+
+	got, err := products.FindByID(other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Price != other.Price {
+		t.Errorf("price of %s changed to %v", other.Name, got.Price)
+	}
+	got, err = products.FindByID(supplied.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Supplier == nil || got.Supplier.Name != supplier.Name {
+		t.Errorf("supplier of %s = %+v", got.Name, got.Supplier)
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository defines methods to interact with the login_challenges and user_recovery_codes tables.
+type TwoFactorRepository interface {
+	CreateChallenge(challenge *models.LoginChallenge) error
+	FindChallenge(tokenHash string) (*models.LoginChallenge, error)
+	ConsumeChallenge(id uint) error
+	AddChallengeAttempt(id uint) error
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userID uint) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) TwoFactorRepository
+}
+
+// TwoFactorRepositoryImpl is the implementation of the TwoFactorRepository interface.
+type TwoFactorRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new instance of TwoFactorRepositoryImpl
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &TwoFactorRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *TwoFactorRepositoryImpl) WithContext(ctx context.Context) TwoFactorRepository {
+	return &TwoFactorRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *TwoFactorRepositoryImpl) CreateChallenge(challenge *models.LoginChallenge) error {
+	return r.DB.Create(challenge).Error
+}
+
+func (r *TwoFactorRepositoryImpl) FindChallenge(tokenHash string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	err := r.DB.Where("token_hash = ?", tokenHash).First(&challenge).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// ConsumeChallenge marks a challenge as used. It fails when it was already
+// used, so one challenge cannot open two sessions.
+func (r *TwoFactorRepositoryImpl) ConsumeChallenge(id uint) error {
+	res := r.DB.Model(&models.LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.Conflict("login_challenge_used", "login challenge already used")
+	}
+	return nil
+}
+
+func (r *TwoFactorRepositoryImpl) AddChallengeAttempt(id uint) error {
+	return r.DB.Model(&models.LoginChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// ReplaceRecoveryCodes drops the old recovery codes of a user and stores the new ones.
+func (r *TwoFactorRepositoryImpl) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.UserRecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: time.Now()})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode consumes an unused recovery code and reports whether one matched.
+func (r *TwoFactorRepositoryImpl) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	res := r.DB.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepositoryImpl) DeleteRecoveryCodes(userID uint) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+}
@@ -2,16 +2,25 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 
 type UserRepository interface {
 	FindUserByEmail(email string) (*models.User, error)
+	FindByID(id uint) (*models.User, error)
+	FindAll(page int, limit int) ([]models.User, int64, error)
 	CreateUser(user *models.User) error
+	Update(user *models.User) error
+	UpdateKeepingPermission(user *models.User, permission string) error
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) UserRepository
 }
 
 // ProductRepositoryImpl is the implementation of the ProductRepository interface.
@@ -24,6 +33,10 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &UserRepositoryImpl{DB: db}
 }
 
+func (r *UserRepositoryImpl) WithContext(ctx context.Context) UserRepository {
+	return &UserRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
 // FindByEmail retrieves a user by their email
 func (r *UserRepositoryImpl) FindUserByEmail(email string) (*models.User, error) {
 	var user models.User
@@ -36,7 +49,77 @@ func (r *UserRepositoryImpl) FindUserByEmail(email string) (*models.User, error)
 	return &user, nil
 }
 
+// FindByID retrieves a user by their ID
+func (r *UserRepositoryImpl) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // Return nil if the user is not found
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindAll retrieves users with pagination
+func (r *UserRepositoryImpl) FindAll(page int, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	if err := r.DB.Model(&models.User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.DB.Order("id")
+	if page > 0 && limit > 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
 // Create creates a new user in the database
 func (r *UserRepositoryImpl) CreateUser(user *models.User) error {
 	return r.DB.Create(user).Error
 }
+
+// Update saves all fields of an existing user
+func (r *UserRepositoryImpl) Update(user *models.User) error {
+	return r.DB.Save(user).Error
+}
+
+// UpdateKeepingPermission saves a user unless that leaves no active user whose
+// role grants permission. The active holders are locked first, so concurrent
+// demotions or deactivations wait for each other and cannot both pass.
+func (r *UserRepositoryImpl) UpdateKeepingPermission(user *models.User, permission string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		holders := func() *gorm.DB {
+			roles := tx.Model(&models.Role{}).
+				Select("roles.name").
+				Joins("JOIN role_permissions rp ON rp.role_id = roles.id").
+				Where("rp.permission = ?", permission)
+			return tx.Model(&models.User{}).Where("is_active = ? AND role IN (?)", true, roles)
+		}
+
+		var locked []models.User
+		if err := holders().Clauses(clause.Locking{Strength: "UPDATE"}).Order("id").Find(&locked).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+
+		var remaining int64
+		if err := holders().Count(&remaining).Error; err != nil {
+			return err
+		}
+		if len(locked) > 0 && remaining == 0 {
+			return apperror.Conflict("last_admin", "cannot remove the last user who can manage roles")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// UserAuditLogRepository defines methods to interact with the user_audit_logs table.
+type UserAuditLogRepository interface {
+	Create(log *models.UserAuditLog) error
+	FindByUserID(userID uint) ([]models.UserAuditLog, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) UserAuditLogRepository
+}
+
+// UserAuditLogRepositoryImpl is the implementation of the UserAuditLogRepository interface.
+type UserAuditLogRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewUserAuditLogRepository creates a new instance of UserAuditLogRepositoryImpl
+func NewUserAuditLogRepository(db *gorm.DB) UserAuditLogRepository {
+	return &UserAuditLogRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *UserAuditLogRepositoryImpl) WithContext(ctx context.Context) UserAuditLogRepository {
+	return &UserAuditLogRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *UserAuditLogRepositoryImpl) Create(log *models.UserAuditLog) error {
+	return r.DB.Create(log).Error
+}
+
+// FindByUserID fetches the changes made to a user, newest first.
+func (r *UserAuditLogRepositoryImpl) FindByUserID(userID uint) ([]models.UserAuditLog, error) {
+	var logs []models.UserAuditLog
+	if err := r.DB.Preload("Actor").Where("target_user_id = ?", userID).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"gorm.io/gorm"
+)
+
+// VehicleRepository defines methods to interact with the vehicles table.
+type VehicleRepository interface {
+	Create(vehicle *models.Vehicle) error
+	FindAll(page int, limit int) ([]models.Vehicle, int64, error)
+	FindByID(id uint) (*models.Vehicle, error)
+	FindByPlate(plate string) (*models.Vehicle, error)
+	// WithContext returns the repository running its queries with ctx
+	WithContext(ctx context.Context) VehicleRepository
+}
+
+// VehicleRepositoryImpl is the implementation of the VehicleRepository interface.
+type VehicleRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewVehicleRepository creates a new instance of VehicleRepositoryImpl
+func NewVehicleRepository(db *gorm.DB) VehicleRepository {
+	return &VehicleRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (r *VehicleRepositoryImpl) WithContext(ctx context.Context) VehicleRepository {
+	return &VehicleRepositoryImpl{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *VehicleRepositoryImpl) Create(vehicle *models.Vehicle) error {
+	return r.DB.Create(vehicle).Error
+}
+
+func (r *VehicleRepositoryImpl) FindAll(page int, limit int) ([]models.Vehicle, int64, error) {
+	var vehicles []models.Vehicle
+	var total int64
+
+	if err := r.DB.Model(&models.Vehicle{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.DB.Order("plate_number")
+	if page > 0 && limit > 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	if err := query.Find(&vehicles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return vehicles, total, nil
+}
+
+func (r *VehicleRepositoryImpl) FindByID(id uint) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	err := r.DB.First(&vehicle, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
+
+func (r *VehicleRepositoryImpl) FindByPlate(plate string) (*models.Vehicle, error) {
+	var vehicle models.Vehicle
+	err := r.DB.Where("plate_number = ?", plate).First(&vehicle).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicle, nil
+}
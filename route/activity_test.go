@@ -0,0 +1,33 @@
+package route_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/models"
+)
+
+func TestLongestBranchFitsTheInvoiceNumber(t *testing.T) {
+	s := newServer(t)
+	token := s.login("budi@example.com", models.RoleAdmin)
+	product := s.createProduct("Oli Mesin")
+	sale := func(branch string) gin.H {
+		return gin.H{"products": []gin.H{{"id": product.ID, "quantity": 1}}, "branch": branch}
+	}
+
+	w := s.do(http.MethodPost, "/activities", token, sale(strings.Repeat("b", 32)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("selling: %d %s", w.Code, w.Body)
+	}
+	var activity models.Activity
+	decode(t, w, &activity)
+	// invoice_number is varchar(50) on MySQL and Postgres
+	if activity.InvoiceNumber == nil || len(*activity.InvoiceNumber) > 50 {
+		t.Errorf("invoice number %v does not fit in 50 characters", activity.InvoiceNumber)
+	}
+
+	w = s.do(http.MethodPost, "/activities", token, sale(strings.Repeat("b", 33)))
+	expectError(t, w, http.StatusBadRequest, "invalid_request")
+}
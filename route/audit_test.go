@@ -0,0 +1,81 @@
+package route_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/models"
+)
+
+// auditEntry returns the audit log written for action, failing when there is none
+func (s *server) auditEntry(entity, action string) models.AuditLog {
+	s.t.Helper()
+	var entry models.AuditLog
+	if err := s.db.Where("entity = ? AND action = ?", entity, action).Last(&entry).Error; err != nil {
+		s.t.Fatalf("no audit log for %s %s: %v", entity, action, err)
+	}
+	return entry
+}
+
+func TestAccountRoutesAreAudited(t *testing.T) {
+	s := newServer(t)
+	s.login("budi@example.com", models.RoleKaryawan)
+	id := s.userID("budi@example.com")
+
+	entry := s.auditEntry("users", "register")
+	if entry.ActorID == nil || *entry.ActorID != id || entry.EntityID != strconv.FormatUint(uint64(id), 10) {
+		t.Errorf("register logged actor %v, entity %q; want user %d", entry.ActorID, entry.EntityID, id)
+	}
+
+	w := s.do(http.MethodPost, "/login", "", gin.H{"email": "budi@example.com", "password": testPassword})
+	var login struct {
+		Data struct {
+			Token        string `json:"token"`
+			RefreshToken string `json:"refresh_token"`
+		} `json:"data"`
+	}
+	decode(t, w, &login)
+
+	w = s.do(http.MethodPost, "/refresh", "", gin.H{"refresh_token": login.Data.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: %d %s", w.Code, w.Body)
+	}
+	var refreshed struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	decode(t, w, &refreshed)
+	entry = s.auditEntry("sessions", "refresh")
+	if entry.ActorID == nil || *entry.ActorID != id {
+		t.Errorf("refresh logged actor %v, want %d", entry.ActorID, id)
+	}
+	if strings.Contains(entry.After, login.Data.RefreshToken) || strings.Contains(entry.After, refreshed.Data.Token) {
+		t.Error("the audit log holds a token")
+	}
+
+	if w := s.do(http.MethodPost, "/logout-all", "Bearer "+refreshed.Data.Token, nil); w.Code != http.StatusOK {
+		t.Fatalf("logout-all: %d %s", w.Code, w.Body)
+	}
+	if entry := s.auditEntry("sessions", "logout_all"); entry.ActorID == nil || *entry.ActorID != id {
+		t.Errorf("logout-all logged actor %v, want %d", entry.ActorID, id)
+	}
+}
+
+func TestFailedAccountRequestsAreNotAudited(t *testing.T) {
+	s := newServer(t)
+
+	w := s.do(http.MethodPost, "/refresh", "", gin.H{"refresh_token": "not-a-token"})
+	expectError(t, w, http.StatusUnauthorized, "invalid_refresh_token")
+
+	var count int64
+	if err := s.db.Model(&models.AuditLog{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d audit logs for a failed request", count)
+	}
+}
@@ -0,0 +1,168 @@
+package route_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/health"
+	"github.com/sinscostank/bengkel-inventory/metrics"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/route"
+	"gorm.io/gorm"
+)
+
+const testPassword = "Secret123!"
+
+var registerValidators sync.Once
+
+// server is the API on a fresh SQLite database
+type server struct {
+	t      *testing.T
+	db     *gorm.DB
+	router *gin.Engine
+}
+
+// newServer starts the API on a fresh database; configure may adjust the
+// default configuration first
+func newServer(t *testing.T, configure ...func(cfg *config.Config)) *server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	// The validator gin binds with is global, so it is set up once
+	registerValidators.Do(func() {
+		validate := binding.Validator.Engine().(*validator.Validate)
+		if err := forms.RegisterValidators(validate); err != nil {
+			t.Fatalf("registering validators: %v", err)
+		}
+		if err := forms.RegisterTranslations(validate); err != nil {
+			t.Fatalf("registering translations: %v", err)
+		}
+	})
+
+	conn := dbtest.Open(t)
+	cfg := config.Default()
+	cfg.JWT.Secret = "test-secret"
+	for _, f := range configure {
+		f(cfg)
+	}
+
+	router, err := route.SetupRoutes(conn, cfg, health.NewChecker(&health.Readiness{}), metrics.New())
+	if err != nil {
+		t.Fatalf("setting up routes: %v", err)
+	}
+	return &server{t: t, db: conn, router: router}
+}
+
+// do sends a request with an optional JSON body and Authorization header
+func (s *server) do(method, path, authorization string, body any) *httptest.ResponseRecorder {
+	s.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("encoding body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// login registers a user with role and returns "Bearer <access token>"
+func (s *server) login(email, role string) string {
+	s.t.Helper()
+	if w := s.do(http.MethodPost, "/register", "", gin.H{"name": "Budi Santoso", "email": email, "password": testPassword}); w.Code != http.StatusCreated {
+		s.t.Fatalf("register: %d %s", w.Code, w.Body)
+	}
+	if err := s.db.Model(&models.User{}).Where("email = ?", email).Update("role", role).Error; err != nil {
+		s.t.Fatalf("setting role: %v", err)
+	}
+	return s.signIn(email, testPassword)
+}
+
+// signIn logs an existing user in and returns "Bearer <access token>"
+func (s *server) signIn(email, password string) string {
+	s.t.Helper()
+	w := s.do(http.MethodPost, "/login", "", gin.H{"email": email, "password": password})
+	if w.Code != http.StatusOK {
+		s.t.Fatalf("login: %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	decode(s.t, w, &resp)
+	return "Bearer " + resp.Data.Token
+}
+
+// userID looks up the ID of the user with email
+func (s *server) userID(email string) uint {
+	s.t.Helper()
+	var user models.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		s.t.Fatalf("finding %s: %v", email, err)
+	}
+	return user.ID
+}
+
+// createProduct stocks 10 of a product priced 10.000
+func (s *server) createProduct(name string) *models.Product {
+	s.t.Helper()
+	category := models.Category{Name: "Kategori " + name}
+	if err := s.db.Create(&category).Error; err != nil {
+		s.t.Fatalf("creating category: %v", err)
+	}
+	product := &models.Product{Name: name, Stock: 10, Price: 10000, Location: "Rak A", CategoryID: category.ID}
+	if err := s.db.Create(product).Error; err != nil {
+		s.t.Fatalf("creating product: %v", err)
+	}
+	return product
+}
+
+// envelope is the body of every error response
+type envelope struct {
+	Error   string         `json:"error"`
+	Code    string         `json:"code"`
+	Details map[string]any `json:"details"`
+}
+
+// expectError checks the status and the error envelope of a response
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) envelope {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+	var env envelope
+	decode(t, w, &env)
+	if env.Code != code || env.Error == "" {
+		t.Fatalf("envelope = %+v, want code %s and a message", env, code)
+	}
+	return env
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+}
@@ -0,0 +1,56 @@
+package route_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/models"
+)
+
+func withMetricsToken(token string) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Metrics.Token = config.Secret(token)
+	}
+}
+
+func TestMetricsAreClosedWithoutAToken(t *testing.T) {
+	s := newServer(t)
+
+	w := s.do(http.MethodGet, "/metrics", "", nil)
+	expectError(t, w, http.StatusForbidden, "metrics_disabled")
+}
+
+func TestMetricsNeedTheToken(t *testing.T) {
+	s := newServer(t, withMetricsToken("scrape"))
+
+	w := s.do(http.MethodGet, "/metrics", "Bearer wrong", nil)
+	expectError(t, w, http.StatusUnauthorized, "invalid_metrics_token")
+
+	if w := s.do(http.MethodGet, "/metrics", "Bearer scrape", nil); w.Code != http.StatusOK {
+		t.Fatalf("scrape: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestRejectedActivitiesAreCounted(t *testing.T) {
+	s := newServer(t, withMetricsToken("scrape"))
+	token := s.login("budi@example.com", models.RoleAdmin)
+
+	// Refused while binding, before the service runs
+	w := s.do(http.MethodPost, "/activities", token, gin.H{"products": "oli"})
+	expectError(t, w, http.StatusBadRequest, "invalid_request")
+	w = s.do(http.MethodPost, "/activities", token, gin.H{"products": []gin.H{{"id": 999, "quantity": 1}}})
+	expectError(t, w, http.StatusNotFound, "product_not_found")
+
+	w = s.do(http.MethodGet, "/metrics", "Bearer scrape", nil)
+	for _, series := range []string{
+		`bengkel_activity_failures_total{reason="invalid_request",type="outbound"} 1`,
+		`bengkel_activity_failures_total{reason="product_not_found",type="outbound"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), series) {
+			t.Errorf("metrics lack %s", series)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package route_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/utils/smtptest"
+)
+
+var resetCode = regexp.MustCompile(`(?m)^([A-Za-z0-9_-]{43})\r?$`)
+
+func withSMTP(smtp *smtptest.Server) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		cfg.SMTP.Host = smtp.Host
+		cfg.SMTP.Port = smtp.Port
+	}
+}
+
+func TestForgotPasswordMailsAWorkingResetCode(t *testing.T) {
+	smtp := smtptest.NewServer(t)
+	s := newServer(t, withSMTP(smtp))
+	s.login("budi@example.com", models.RoleKaryawan)
+
+	w := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "budi@example.com"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("forgot: %d %s", w.Code, w.Body)
+	}
+
+	mail := smtp.Next(t)
+	if len(mail.To) != 1 || mail.To[0] != "budi@example.com" {
+		t.Fatalf("mail sent to %v", mail.To)
+	}
+	match := resetCode.FindStringSubmatch(mail.Data)
+	if match == nil {
+		t.Fatalf("no reset code in the mail:\n%s", mail.Data)
+	}
+
+	w = s.do(http.MethodPost, "/password/reset", "", gin.H{"token": match[1], "new_password": "Baru12345!", "confirm_password": "Baru12345!"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodPost, "/login", "", gin.H{"email": "budi@example.com", "password": "Baru12345!"}); w.Code != http.StatusOK {
+		t.Fatalf("login with the new password: %d %s", w.Code, w.Body)
+	}
+
+	// The code works once
+	w = s.do(http.MethodPost, "/password/reset", "", gin.H{"token": match[1], "new_password": "Lain12345!", "confirm_password": "Lain12345!"})
+	expectError(t, w, http.StatusBadRequest, "invalid_reset_token")
+}
+
+func TestForgotPasswordAnswersTheSameForEveryEmail(t *testing.T) {
+	smtp := smtptest.NewServer(t)
+	s := newServer(t, withSMTP(smtp))
+	s.login("budi@example.com", models.RoleKaryawan)
+
+	known := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "budi@example.com"})
+	smtp.Next(t)
+	unknown := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "nobody@example.com"})
+
+	if known.Code != http.StatusOK || unknown.Code != known.Code || unknown.Body.String() != known.Body.String() {
+		t.Fatalf("known: %d %s, unknown: %d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+	if smtp.Count() != 0 {
+		t.Error("a mail was sent to an unknown email")
+	}
+}
+
+func TestForgotPasswordHidesMailFailures(t *testing.T) {
+	s := newServer(t, func(cfg *config.Config) {
+		// Nothing listens on port 1
+		cfg.SMTP.Host = "127.0.0.1"
+		cfg.SMTP.Port = "1"
+	})
+	s.login("budi@example.com", models.RoleKaryawan)
+
+	known := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "budi@example.com"})
+	unknown := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "nobody@example.com"})
+	if known.Code != http.StatusOK || unknown.Body.String() != known.Body.String() {
+		t.Fatalf("known: %d %s, unknown: %d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+}
+
+func TestForgotPasswordIsRateLimited(t *testing.T) {
+	smtp := smtptest.NewServer(t)
+	s := newServer(t, withSMTP(smtp), func(cfg *config.Config) {
+		cfg.Login.MaxResetRequests = 2
+		cfg.Login.MaxResetRequestsPerIP = 3
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "nobody@example.com"}); w.Code != http.StatusOK {
+			t.Fatalf("request %d: %d %s", i+1, w.Code, w.Body)
+		}
+	}
+	w := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "nobody@example.com"})
+	expectError(t, w, http.StatusTooManyRequests, "reset_throttled")
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After header")
+	}
+
+	// Another email from the same IP still has one request left
+	if w := s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "other@example.com"}); w.Code != http.StatusOK {
+		t.Fatalf("other email: %d %s", w.Code, w.Body)
+	}
+	w = s.do(http.MethodPost, "/password/forgot", "", gin.H{"email": "third@example.com"})
+	expectError(t, w, http.StatusTooManyRequests, "reset_throttled")
+}
@@ -0,0 +1,60 @@
+package route_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/models"
+)
+
+// sell records a sale of one product and returns the activity ID
+func (s *server) sell(authorization string, product *models.Product, paymentStatus string) uint {
+	s.t.Helper()
+	w := s.do(http.MethodPost, "/activities", authorization, gin.H{
+		"products":       []gin.H{{"id": product.ID, "quantity": 1}},
+		"payment_status": paymentStatus,
+	})
+	if w.Code != http.StatusCreated {
+		s.t.Fatalf("selling: %d %s", w.Code, w.Body)
+	}
+	var activity struct {
+		ID uint `json:"id"`
+	}
+	decode(s.t, w, &activity)
+	return activity.ID
+}
+
+func TestReceiptPrintsThePaymentStatus(t *testing.T) {
+	s := newServer(t)
+	token := s.login("budi@example.com", models.RoleAdmin)
+
+	product := s.createProduct("Oli Mesin")
+
+	paid := s.sell(token, product, "paid")
+	w := s.do(http.MethodGet, fmt.Sprintf("/activities/%d/receipt?format=text&paid=20000", paid), token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("receipt: %d %s", w.Code, w.Body)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "LUNAS") || !strings.Contains(body, "Kembali") || strings.Contains(body, "success") {
+		t.Errorf("paid receipt:\n%s", body)
+	}
+
+	unpaid := s.sell(token, product, "unpaid")
+	w = s.do(http.MethodGet, fmt.Sprintf("/activities/%d/receipt?format=text", unpaid), token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("receipt: %d %s", w.Code, w.Body)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "BELUM DIBAYAR") {
+		t.Errorf("unpaid receipt:\n%s", body)
+	}
+
+	// Cash can't be tendered on an unpaid sale
+	w = s.do(http.MethodGet, fmt.Sprintf("/activities/%d/receipt?format=text&paid=20000", unpaid), token, nil)
+	expectError(t, w, http.StatusBadRequest, "activity_unpaid")
+
+	w = s.do(http.MethodGet, fmt.Sprintf("/activities/%d/receipt?format=text&paid=NaN", paid), token, nil)
+	expectError(t, w, http.StatusBadRequest, "invalid_paid_amount")
+}
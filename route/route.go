@@ -1,52 +1,132 @@
 package route
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/config"
 	"github.com/sinscostank/bengkel-inventory/controller"
+	"github.com/sinscostank/bengkel-inventory/health"
+	"github.com/sinscostank/bengkel-inventory/metrics"
 	"github.com/sinscostank/bengkel-inventory/middleware"
+	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/repository"
 	"github.com/sinscostank/bengkel-inventory/service"
+	"github.com/sinscostank/bengkel-inventory/utils"
 	"gorm.io/gorm"
 
 )
 
 func SetupRoutes(
 	dbConn *gorm.DB,
-) *gin.Engine {
+	cfg *config.Config,
+	checker *health.Checker,
+	m *metrics.Metrics,
+) (*gin.Engine, error) {
 
 	// Create repository
 	userRepo := repository.NewUserRepository(dbConn)
 	productRepo := repository.NewProductRepository(dbConn)
 	categoryRepo := repository.NewCategoryRepository(dbConn)
 	activityRepo := repository.NewActivityRepository(dbConn)
-	activityItemRepo := repository.NewActivityItemRepository(dbConn)
-	stockTransactionRepo := repository.NewStockTransactionRepository(dbConn)
 	priceHistoryRepo := repository.NewPriceHistoryRepository(dbConn)
+	receiptTemplateRepo := repository.NewReceiptTemplateRepository(dbConn)
+	fitmentRepo := repository.NewProductFitmentRepository(dbConn)
+	vehicleRepo := repository.NewVehicleRepository(dbConn)
+	supplierRepo := repository.NewSupplierRepository(dbConn)
+	userAuditLogRepo := repository.NewUserAuditLogRepository(dbConn)
+	sessionRepo := repository.NewSessionRepository(dbConn)
+	roleRepo := repository.NewRoleRepository(dbConn)
+	passwordResetRepo := repository.NewPasswordResetTokenRepository(dbConn)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(dbConn)
+	twoFactorRepo := repository.NewTwoFactorRepository(dbConn)
+	apiKeyRepo := repository.NewAPIKeyRepository(dbConn)
+	auditLogRepo := repository.NewAuditLogRepository(dbConn)
+	scheduledPriceRepo := repository.NewScheduledPriceChangeRepository(dbConn)
+
+	// Create services shared by several controllers
+	sessionService := service.NewSessionService(sessionRepo, userRepo, cfg.JWT)
+	permissionService := service.NewPermissionService(roleRepo)
+	loginGuardService := service.NewLoginGuardService(loginAttemptRepo, cfg.Login)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, permissionService)
+	auditService := service.NewAuditService(auditLogRepo)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, userAuditLogRepo, permissionService, sessionService, loginGuardService, cfg.Login)
 
 	// Create controllers
-	userController := controller.NewUserController(service.NewUserService(userRepo))
-	productController := controller.NewProductController(service.NewProductService(productRepo, categoryRepo, priceHistoryRepo))
+	userController := controller.NewUserController(service.NewUserService(userRepo, sessionService, loginGuardService, twoFactorService), sessionService)
+	userAdminController := controller.NewUserAdminController(service.NewUserAdminService(userRepo, userAuditLogRepo, roleRepo, sessionService, loginGuardService))
+	roleController := controller.NewRoleController(permissionService)
+	twoFactorController := controller.NewTwoFactorController(twoFactorService)
+	apiKeyController := controller.NewAPIKeyController(apiKeyService)
+	auditLogController := controller.NewAuditLogController(auditService)
+	priceController := controller.NewPriceController(service.NewPriceService(productRepo, priceHistoryRepo, scheduledPriceRepo, categoryRepo, supplierRepo))
+	passwordController := controller.NewPasswordController(service.NewPasswordService(userRepo, passwordResetRepo, userAuditLogRepo, loginAttemptRepo, sessionService, utils.NewMailer(cfg.SMTP), cfg.Login))
+	productController := controller.NewProductController(service.NewProductService(productRepo, categoryRepo, supplierRepo, priceHistoryRepo))
 	categoryController := controller.NewCategoryController(service.NewCategoryService(categoryRepo))
-	activityController := controller.NewActivityController(service.NewActivityService(activityRepo, productRepo, activityItemRepo, stockTransactionRepo))
+	receiptService := service.NewReceiptService(activityRepo, receiptTemplateRepo, cfg.Workshop)
+	activityController := controller.NewActivityController(service.NewActivityService(activityRepo, productRepo, permissionService, cfg.Workshop), service.NewInvoiceService(activityRepo, receiptService), m)
+	receiptController := controller.NewReceiptController(receiptService)
+	fitmentController := controller.NewProductFitmentController(service.NewProductFitmentService(fitmentRepo, productRepo, vehicleRepo))
+	vehicleController := controller.NewVehicleController(service.NewVehicleService(vehicleRepo))
+	supplierController := controller.NewSupplierController(service.NewSupplierService(supplierRepo))
+	healthController := controller.NewHealthController(checker)
 
 
 	// Initialize Gin router
-	r := gin.Default()
+	r := gin.New()
+
+	// c.ClientIP() only follows X-Forwarded-For from the configured proxies,
+	// so clients cannot forge the address API key allowlists and login
+	// throttling rely on. The list is checked by config.Validate.
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("setting trusted proxies: %w", err)
+	}
+
+	// Every request gets an ID, one structured log line and its metrics
+	r.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.RequestMetrics(m), middleware.Recovery())
+
+	// Domain errors left on the context by handlers become the JSON error envelope
+	r.Use(middleware.ErrorHandler())
+
+	// can builds the middleware guarding a route with permissions
+	can := func(permissions ...string) gin.HandlerFunc {
+		return middleware.RequirePermission(permissionService, permissions...)
+	}
+
+	// Session, not available to API keys
+	sessionGroup := r.Group("", middleware.AuthMiddleware(sessionService, apiKeyService), middleware.UserOnly(), middleware.Audit(auditService))
+	{
+		sessionGroup.POST("/logout", userController.Logout)
+		sessionGroup.POST("/logout-all", userController.LogoutAll)
+	}
 
-	authenticatedGroup := r.Group("", middleware.AuthMiddleware())
+	// Every change made below is written to the audit log
+	authenticatedGroup := r.Group("", middleware.AuthMiddleware(sessionService, apiKeyService), middleware.Audit(auditService))
 	{
+		// Own account, not available to API keys
+		accountGroup := authenticatedGroup.Group("/me", middleware.UserOnly())
+		{
+			accountGroup.POST("/password", passwordController.ChangePassword)
+			accountGroup.POST("/2fa/enroll", twoFactorController.Enroll)
+			accountGroup.POST("/2fa/confirm", twoFactorController.Confirm)
+			accountGroup.POST("/2fa/disable", twoFactorController.Disable)
+			accountGroup.POST("/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+		}
+
 		// Category
 		categoryGroup := authenticatedGroup.Group("/categories")
 		{
 			categoryGroup.GET("", categoryController.GetCategories)
+			categoryGroup.GET("/tree", categoryController.GetCategoryTree)
 			categoryGroup.GET("/:id", categoryController.GetCategoryByID)
+			categoryGroup.GET("/:id/tree", categoryController.GetCategorySubtree)
 	
-			// Admin routes for categories
-			adminCategoryGroup := categoryGroup.Group("", middleware.AdminMiddleware())
+			// Write routes for categories
+			adminCategoryGroup := categoryGroup.Group("", can(models.PermCategoryWrite))
 			{
-				// Admin only routes
 				adminCategoryGroup.POST("", categoryController.CreateCategory)
 				adminCategoryGroup.PUT("/:id", categoryController.UpdateCategory)
+				adminCategoryGroup.PUT("/:id/move", categoryController.MoveCategory)
 				adminCategoryGroup.DELETE("/:id", categoryController.DeleteCategory)
 			}
 		
@@ -57,40 +137,138 @@ func SetupRoutes(
 		{
 			productGroup.GET("", productController.GetProducts)
 			productGroup.GET("/:id", productController.GetProductByID)
+			productGroup.GET("/:id/fitments", fitmentController.GetProductFitments)
+			productGroup.GET("/:id/price-history", priceController.GetPriceHistory)
+			productGroup.GET("/:id/scheduled-prices", priceController.GetScheduledPrices)
 		
-			// Admin routes for products
-			adminProductGroup := productGroup.Group("", middleware.AdminMiddleware())
+			// Write routes for products
+			adminProductGroup := productGroup.Group("", can(models.PermProductWrite))
 			{
 				adminProductGroup.POST("", productController.CreateProduct)
+				adminProductGroup.POST("/bulk-price", priceController.BulkUpdatePrices)
 				adminProductGroup.PUT("/:id", productController.UpdateProduct)
 				adminProductGroup.DELETE("/:id", productController.DeleteProduct)
+				adminProductGroup.POST("/:id/fitments", fitmentController.CreateProductFitment)
+				adminProductGroup.POST("/:id/scheduled-prices", priceController.SchedulePrice)
+			}
+		}
+
+		// Scheduled price changes
+		authenticatedGroup.DELETE("/scheduled-prices/:id", can(models.PermProductWrite), priceController.CancelScheduledPrice)
+
+		// Fitment
+		fitmentGroup := authenticatedGroup.Group("/fitments")
+		{
+			fitmentGroup.GET("/search", fitmentController.SearchCompatibleProducts)
+
+			adminFitmentGroup := fitmentGroup.Group("", can(models.PermProductWrite))
+			{
+				adminFitmentGroup.POST("/import", fitmentController.ImportFitments)
+				adminFitmentGroup.DELETE("/:id", fitmentController.DeleteFitment)
 			}
 		}
+
+		// Vehicle registry
+		vehicleGroup := authenticatedGroup.Group("/vehicles")
+		{
+			vehicleGroup.GET("", vehicleController.GetVehicles)
+			vehicleGroup.GET("/:id", vehicleController.GetVehicleByID)
+			vehicleGroup.POST("", vehicleController.CreateVehicle)
+		}
+
+		// Suppliers
+		supplierGroup := authenticatedGroup.Group("/suppliers")
+		{
+			supplierGroup.GET("", supplierController.GetSuppliers)
+			supplierGroup.GET("/:id", supplierController.GetSupplierByID)
+			supplierGroup.POST("", can(models.PermProductWrite), supplierController.CreateSupplier)
+		}
 	
 		// Product
 		activitiesGroup := authenticatedGroup.Group("/activities")
 		{
 			activitiesGroup.GET("", activityController.GetActivities)
-			activitiesGroup.POST("", activityController.CreateActivity)
+			activitiesGroup.POST("", can(models.PermSaleCreate), activityController.CreateActivity)
+			activitiesGroup.GET("/:id/receipt", receiptController.GetReceipt)
+			activitiesGroup.GET("/:id/invoice", activityController.GetInvoice)
+		}
+
+		// Receipt templates
+		receiptTemplateGroup := authenticatedGroup.Group("/receipt-templates", can(models.PermSettingsManage))
+		{
+			receiptTemplateGroup.GET("/:branch", receiptController.GetTemplate)
+			receiptTemplateGroup.PUT("/:branch", receiptController.UpdateTemplate)
 		}
 	
 		// Stock Transactions
-		authenticatedGroup.POST("/stock-transactions",  middleware.AdminMiddleware(), activityController.CreateActivity) 
+		authenticatedGroup.POST("/stock-transactions", can(models.PermStockAdjust), activityController.CreateActivity)
 	
+		// User management
+		userGroup := authenticatedGroup.Group("/users", can(models.PermUserManage))
+		{
+			userGroup.GET("", userAdminController.GetUsers)
+			userGroup.POST("", userAdminController.CreateUser)
+			userGroup.GET("/:id", userAdminController.GetUserByID)
+			userGroup.PUT("/:id", userAdminController.UpdateUser)
+			userGroup.PUT("/:id/role", userAdminController.ChangeUserRole)
+			userGroup.POST("/:id/deactivate", userAdminController.DeactivateUser)
+			userGroup.POST("/:id/reactivate", userAdminController.ReactivateUser)
+			userGroup.POST("/:id/reset-password", userAdminController.ForcePasswordReset)
+			userGroup.POST("/:id/send-reset-link", passwordController.SendResetLink)
+			userGroup.GET("/:id/audit", userAdminController.GetUserAuditTrail)
+			userGroup.POST("/:id/unlock", userAdminController.UnlockUser)
+			userGroup.GET("/:id/logins", userAdminController.GetUserLogins)
+			userGroup.POST("/:id/2fa/reset", twoFactorController.ResetUser)
+		}
+
+		// Roles & permissions
+		roleGroup := authenticatedGroup.Group("", can(models.PermRoleManage))
+		{
+			roleGroup.GET("/permissions", roleController.GetPermissions)
+			roleGroup.GET("/roles", roleController.GetRoles)
+			roleGroup.POST("/roles", roleController.CreateRole)
+			roleGroup.PUT("/roles/:name/permissions", roleController.UpdateRolePermissions)
+			roleGroup.PUT("/roles/:name/two-factor", roleController.UpdateRoleTwoFactor)
+			roleGroup.DELETE("/roles/:name", roleController.DeleteRole)
+		}
+
+		// API keys for integrations
+		apiKeyGroup := authenticatedGroup.Group("/api-keys", can(models.PermUserManage))
+		{
+			apiKeyGroup.GET("", apiKeyController.GetAPIKeys)
+			apiKeyGroup.POST("", apiKeyController.CreateAPIKey)
+			apiKeyGroup.DELETE("/:id", apiKeyController.RevokeAPIKey)
+		}
+
+		// Audit log
+		authenticatedGroup.GET("/audit-logs", can(models.PermSettingsManage), auditLogController.GetAuditLogs)
+
 		// Sales Report
-		authenticatedGroup.GET("/sales-report", productController.SalesReport)
+		authenticatedGroup.GET("/sales-report", can(models.PermReportSales), productController.SalesReport)
+		authenticatedGroup.GET("/sales-report/categories", can(models.PermReportSales), categoryController.CategorySalesReport)
 	}
 
 	// Health‐check
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
+	r.GET("/healthz", healthController.Live)
+	r.GET("/readyz", healthController.Ready)
+	r.GET("/metrics", middleware.MetricsToken(cfg.Metrics.Token.Value()), gin.WrapH(m.Handler()))
 
 	// User
-	r.POST("/register", userController.RegisterUser)
 	r.POST("/login", userController.LoginUser)
+	r.POST("/login/2fa", twoFactorController.VerifyLogin)
+	r.POST("/login/2fa/enroll", twoFactorController.EnrollLogin)
+	accountGroup := r.Group("", middleware.Audit(auditService))
+	{
+		accountGroup.POST("/register", userController.RegisterUser)
+		accountGroup.POST("/refresh", userController.RefreshToken)
+		accountGroup.POST("/password/forgot", passwordController.ForgotPassword)
+		accountGroup.POST("/password/reset", passwordController.ResetPassword)
+	}
 
 
-	return r
+	return r, nil
 
 }
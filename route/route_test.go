@@ -0,0 +1,146 @@
+package route_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/db/dbtest"
+	"github.com/sinscostank/bengkel-inventory/health"
+	"github.com/sinscostank/bengkel-inventory/metrics"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/route"
+)
+
+func TestProtectedRoutesNeedAuthentication(t *testing.T) {
+	s := newServer(t)
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          string
+	}{
+		{"no header", "", "authorization_required"},
+		{"wrong scheme", "Token abc", "invalid_authorization"},
+		{"invalid token", "Bearer not-a-jwt", "invalid_token"},
+		{"unknown api key", "ApiKey bk_unknown", "invalid_api_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectError(t, s.do(http.MethodGet, "/products", tt.authorization, nil), http.StatusUnauthorized, tt.code)
+		})
+	}
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	s := newServer(t)
+	s.login("budi@example.com", models.RoleKaryawan)
+
+	w := s.do(http.MethodPost, "/login", "", gin.H{"email": "budi@example.com", "password": "Wrong123!"})
+	expectError(t, w, http.StatusUnauthorized, "invalid_credentials")
+
+	// Unknown emails get the same answer
+	w = s.do(http.MethodPost, "/login", "", gin.H{"email": "nobody@example.com", "password": "Wrong123!"})
+	expectError(t, w, http.StatusUnauthorized, "invalid_credentials")
+}
+
+func TestLogoutRevokesTheSession(t *testing.T) {
+	s := newServer(t)
+	token := s.login("budi@example.com", models.RoleKaryawan)
+
+	if w := s.do(http.MethodGet, "/products", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("products: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodPost, "/logout", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("logout: %d %s", w.Code, w.Body)
+	}
+	expectError(t, s.do(http.MethodGet, "/products", token, nil), http.StatusUnauthorized, "session_revoked")
+}
+
+func TestPermissionsFollowTheRole(t *testing.T) {
+	s := newServer(t)
+	admin := s.login("admin@example.com", models.RoleAdmin)
+	karyawan := s.login("karyawan@example.com", models.RoleKaryawan)
+
+	category := gin.H{"name": "Oli"}
+	expectError(t, s.do(http.MethodPost, "/categories", karyawan, category), http.StatusForbidden, "permission_denied")
+	expectError(t, s.do(http.MethodGet, "/users", karyawan, nil), http.StatusForbidden, "permission_denied")
+
+	if w := s.do(http.MethodPost, "/categories", admin, category); w.Code != http.StatusCreated {
+		t.Fatalf("admin creating a category: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodGet, "/users", admin, nil); w.Code != http.StatusOK {
+		t.Fatalf("admin listing users: %d %s", w.Code, w.Body)
+	}
+
+	// Granting the permission to karyawan takes effect immediately
+	w := s.do(http.MethodPut, "/roles/karyawan/permissions", admin, gin.H{
+		"permissions": []string{models.PermSaleCreate, models.PermReportSales, models.PermCategoryWrite},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("updating permissions: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodPost, "/categories", karyawan, gin.H{"name": "Ban"}); w.Code != http.StatusCreated {
+		t.Fatalf("karyawan creating a category: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestAPIKeysActWithinTheirScopes(t *testing.T) {
+	s := newServer(t)
+	admin := s.login("admin@example.com", models.RoleAdmin)
+
+	w := s.do(http.MethodPost, "/api-keys", admin, gin.H{"name": "POS", "scopes": []string{models.PermReportSales}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating api key: %d %s", w.Code, w.Body)
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	decode(t, w, &created)
+	key := "ApiKey " + created.Key
+
+	if w := s.do(http.MethodGet, "/sales-report", key, nil); w.Code != http.StatusOK {
+		t.Fatalf("sales report: %d %s", w.Code, w.Body)
+	}
+	expectError(t, s.do(http.MethodPost, "/categories", key, gin.H{"name": "Oli"}), http.StatusForbidden, "permission_denied")
+	expectError(t, s.do(http.MethodPost, "/logout", key, nil), http.StatusForbidden, "api_key_not_allowed")
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	s := newServer(t)
+	admin := s.login("admin@example.com", models.RoleAdmin)
+
+	t.Run("validation errors list the fields", func(t *testing.T) {
+		w := s.do(http.MethodPost, "/register", "", gin.H{"name": "Budi Santoso", "email": "not-an-email", "password": testPassword})
+		env := expectError(t, w, http.StatusBadRequest, "invalid_request")
+		fields, ok := env.Details["fields"].(map[string]any)
+		if !ok || fields["email"] == nil {
+			t.Fatalf("details = %v, want a message for email", env.Details)
+		}
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		w := s.do(http.MethodPost, "/categories", admin, "{")
+		expectError(t, w, http.StatusBadRequest, "invalid_request")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		expectError(t, s.do(http.MethodGet, "/categories/999", admin, nil), http.StatusNotFound, "category_not_found")
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		w := s.do(http.MethodPost, "/register", "", gin.H{"name": "Budi Santoso", "email": "admin@example.com", "password": testPassword})
+		expectError(t, w, http.StatusConflict, "email_in_use")
+	})
+}
+
+func TestSetupRoutesRejectsInvalidTrustedProxies(t *testing.T) {
+	cfg := config.Default()
+	cfg.JWT.Secret = "test-secret"
+	cfg.Server.TrustedProxies = []string{"not-an-address"}
+
+	if _, err := route.SetupRoutes(dbtest.Open(t), cfg, health.NewChecker(&health.Readiness{}), metrics.New()); err == nil {
+		t.Error("SetupRoutes accepted an invalid trusted proxy")
+	}
+}
@@ -0,0 +1,91 @@
+package route_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sinscostank/bengkel-inventory/models"
+)
+
+func TestForcedPasswordResetAllowsOnlyThePasswordChange(t *testing.T) {
+	s := newServer(t)
+	admin := s.login("admin@example.com", models.RoleAdmin)
+	s.login("budi@example.com", models.RoleKaryawan)
+
+	w := s.do(http.MethodPost, fmt.Sprintf("/users/%d/reset-password", s.userID("budi@example.com")), admin, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset: %d %s", w.Code, w.Body)
+	}
+	var reset struct {
+		TemporaryPassword string `json:"temporary_password"`
+	}
+	decode(t, w, &reset)
+
+	budi := s.signIn("budi@example.com", reset.TemporaryPassword)
+	expectError(t, s.do(http.MethodGet, "/products", budi, nil), http.StatusForbidden, "password_reset_required")
+	expectError(t, s.do(http.MethodPost, "/me/2fa/enroll", budi, nil), http.StatusForbidden, "password_reset_required")
+
+	w = s.do(http.MethodPost, "/me/password", budi, gin.H{
+		"old_password":     reset.TemporaryPassword,
+		"new_password":     "Baru12345!",
+		"confirm_password": "Baru12345!",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("changing password: %d %s", w.Code, w.Body)
+	}
+	if w := s.do(http.MethodGet, "/products", budi, nil); w.Code != http.StatusOK {
+		t.Fatalf("products after the change: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestLastRoleManagerCannotBeRemoved(t *testing.T) {
+	s := newServer(t)
+	admin := s.login("admin@example.com", models.RoleAdmin)
+	adminID := s.userID("admin@example.com")
+
+	expectError(t, s.do(http.MethodPut, fmt.Sprintf("/users/%d/role", adminID), admin, gin.H{"role": models.RoleKaryawan}), http.StatusConflict, "last_admin")
+	expectError(t, s.do(http.MethodPost, fmt.Sprintf("/users/%d/deactivate", adminID), admin, nil), http.StatusConflict, "last_admin")
+
+	// A custom role that can manage roles keeps the workshop manageable
+	w := s.do(http.MethodPost, "/roles", admin, gin.H{"name": "owner", "permissions": []string{models.PermRoleManage, models.PermUserManage}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating role: %d %s", w.Code, w.Body)
+	}
+	s.login("owner@example.com", "owner")
+
+	if w := s.do(http.MethodPut, fmt.Sprintf("/users/%d/role", adminID), admin, gin.H{"role": models.RoleKaryawan}); w.Code != http.StatusOK {
+		t.Fatalf("demoting the admin: %d %s", w.Code, w.Body)
+	}
+
+	var admin2 models.User
+	s.db.First(&admin2, adminID)
+	if admin2.Role != models.RoleKaryawan {
+		t.Errorf("role = %s, want karyawan", admin2.Role)
+	}
+}
+
+func TestConcurrentDemotionsKeepOneRoleManager(t *testing.T) {
+	s := newServer(t)
+	first := s.login("first@example.com", models.RoleAdmin)
+	second := s.login("second@example.com", models.RoleAdmin)
+
+	codes := make(chan int, 2)
+	for _, c := range []struct {
+		token string
+		email string
+	}{{first, "second@example.com"}, {second, "first@example.com"}} {
+		go func(token string, id uint) {
+			codes <- s.do(http.MethodPost, fmt.Sprintf("/users/%d/deactivate", id), token, nil).Code
+		}(c.token, s.userID(c.email))
+	}
+	<-codes
+	<-codes
+
+	var active int64
+	s.db.Model(&models.User{}).Where("role = ? AND is_active = ?", models.RoleAdmin, true).Count(&active)
+	if active != 1 {
+		t.Fatalf("%d active admins left, want 1", active)
+	}
+}
@@ -2,66 +2,77 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"log/slog"
 	"time"
-	"fmt"
 
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
 )
 
 type ActivityService interface {
-	GetByID(id uint) (*models.Activity, error)
-	Create(userID uint, userRole string, form *forms.ActivityForm) (*models.Activity, error)
-	GetAll() ([]models.Activity, error)
+	GetByID(ctx context.Context, id uint) (*models.Activity, error)
+	Create(ctx context.Context, caller *utils.UserClaims, form *forms.ActivityForm) (*models.Activity, error)
+	GetAll(ctx context.Context) ([]models.Activity, error)
 }
 
 type activityService struct {
-	activityRepo         repository.ActivityRepository
-	productRepo          repository.ProductRepository
-	activityItemRepo     repository.ActivityItemRepository
-	stockTransactionRepo repository.StockTransactionRepository
+	activityRepo      repository.ActivityRepository
+	productRepo       repository.ProductRepository
+	permissionService PermissionService
+	taxRate           float64
 }
 
 func NewActivityService(
 	activityRepo repository.ActivityRepository,
 	productRepo repository.ProductRepository,
-	activityItemRepo repository.ActivityItemRepository,
-	stockTransactionRepo repository.StockTransactionRepository,
+	permissionService PermissionService,
+	cfg config.Workshop,
 ) ActivityService {
-	return &activityService{activityRepo, productRepo, activityItemRepo, stockTransactionRepo}
+	return &activityService{activityRepo, productRepo, permissionService, cfg.InvoiceTaxRate}
 }
 
-func (s *activityService) GetByID(id uint) (*models.Activity, error) {
-	activity, err := s.activityRepo.FindByID(id)
+func (s *activityService) GetByID(ctx context.Context, id uint) (*models.Activity, error) {
+	activity, err := s.activityRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 	if activity == nil {
-		return nil, errors.New("activity not found")
+		return nil, apperror.NotFound("activity_not_found", "activity not found")
 	}
 	return activity, nil
 }
 
-func (s *activityService) GetAll() ([]models.Activity, error) {
-	activities, err := s.activityRepo.FindAll()
+func (s *activityService) GetAll(ctx context.Context) ([]models.Activity, error) {
+	activities, err := s.activityRepo.WithContext(ctx).FindAll()
 	if err != nil {
 		return nil, err
 	}
 	if activities == nil {
-		return nil, errors.New("activity not found")
+		return nil, apperror.NotFound("activity_not_found", "activity not found")
 	}
 	return activities, nil
 }
 
-func (s *activityService) Create(userID uint, userRole string, form *forms.ActivityForm) (*models.Activity, error) {
+// Create records a sale or a stock adjustment; its queries and logs run with
+// ctx, the request context
+func (s *activityService) Create(ctx context.Context, caller *utils.UserClaims, form *forms.ActivityForm) (*models.Activity, error) {
 	// Validate activity type
 	if form.Type != "inbound" && form.Type != "outbound" {
-		return nil, errors.New("invalid activity type")
+		return nil, apperror.Validation("invalid_activity_type", "invalid activity type")
 	}
-	if form.Type == "inbound" && userRole != "admin" {
-		return nil, errors.New("only admins can create inbound activities")
+	if form.Type == "inbound" {
+		allowed, err := s.permissionService.Allows(ctx, caller, models.PermStockAdjust)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, apperror.Forbidden("inbound_not_allowed", "not allowed to create inbound activities")
+		}
 	}
 
 	// Prepare maps and validate duplicates
@@ -71,48 +82,72 @@ func (s *activityService) Create(userID uint, userRole string, form *forms.Activ
 
 	for i, item := range form.Products {
 		if _, exists := productIDSet[item.ID]; exists {
-			return nil, errors.New("duplicate product ID found")
+			return nil, apperror.Validation("duplicate_product", "duplicate product ID found")
 		}
 		productIDSet[item.ID] = struct{}{}
 		inputMap[item.ID] = item.Quantity
 		productIDs[i] = item.ID
 	}
 
-	products, err := s.productRepo.FindByIDs(productIDs)
-	if err != nil || len(products) != len(form.Products) {
-		return nil, errors.New("product not found or DB error")
+	activityRepo := s.activityRepo.WithContext(ctx)
+	productRepo := s.productRepo.WithContext(ctx)
+
+	products, err := productRepo.FindByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) != len(form.Products) {
+		return nil, apperror.NotFound("product_not_found", "product not found")
 	}
 
-	// Check stock for outbound
+	// Check stock for outbound; the guarded update in CreateWithItems catches
+	// sales that race for the same stock
 	if form.Type == "outbound" {
 		for _, p := range products {
 			if p.Stock < int(inputMap[p.ID]) {
-				return nil, errors.New("insufficient stock for product " + p.Name)
+				return nil, apperror.InsufficientStock(apperror.StockShortage{
+					ProductID:   p.ID,
+					ProductName: p.Name,
+					Available:   p.Stock,
+					Requested:   int(inputMap[p.ID]),
+				})
 			}
 		}
 	}
 
+	branch := form.Branch
+	if branch == "" {
+		branch = DefaultBranch
+	}
+	paymentStatus := form.PaymentStatus
+	if paymentStatus == "" {
+		paymentStatus = "paid"
+	}
+
 	// Create activity
 	activity := models.Activity{
-		UserID: userID,
+		UserID: caller.ID,
+		Branch: branch,
+		CustomerName: form.CustomerName,
+		VehiclePlate: form.VehiclePlate,
+		PaymentStatus: paymentStatus,
 		Status: "success",
 		Type:   form.Type,
 		Date: 	time.Now(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	if err := s.activityRepo.Create(&activity); err != nil {
-		return nil, err
-	}
 
-	fmt.Println("Activity created successfully")
+	// Only sales get an invoice number and the tax rate
+	if form.Type == "outbound" {
+		activity.TaxRate = s.taxRate
+	}
 
 	// Create activity items
 	var activityItems []*models.ActivityItem
 	for _, p := range products {
 		qty := int(inputMap[p.ID])
 		item := &models.ActivityItem{
-			ActivityID:  activity.ID,
 			ProductID:   p.ID,
 			Quantity:    qty,
 			PriceAtTime: p.Price,
@@ -122,47 +157,17 @@ func (s *activityService) Create(userID uint, userRole string, form *forms.Activ
 		}
 		activityItems = append(activityItems, item)
 	}
-	if err := s.activityItemRepo.CreateMultiple(activityItems); err != nil {
-		return nil, err
-	}
-
-	fmt.Println("Activity items created successfully")
 
-
-	// Create stock transactions
-	var transactions []*models.StockTransaction
-	for _, item := range activityItems {
-		change := item.Quantity
-		if form.Type == "outbound" {
-			change = -change
-		}
-		t := &models.StockTransaction{
-			ProductID:      item.ProductID,
-			ChangeQuantity: change,
-			ActivityItemID: &item.ID,
-			Note:           "Stock change for activity",
-			Date: 	   time.Now(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		transactions = append(transactions, t)
-	}
-	if err := s.stockTransactionRepo.CreateMultiple(transactions); err != nil {
+	// The activity, its items, the stock transactions and the stock changes
+	// are written in one transaction
+	if err := activityRepo.CreateWithItems(&activity, activityItems); err != nil {
 		return nil, err
 	}
 
-	// Update product stock
-	for _, p := range products {
-		qty := int(inputMap[p.ID])
-		if form.Type == "inbound" {
-			p.Stock += qty
-		} else {
-			p.Stock -= qty
-		}
-		if err := s.productRepo.Update(&p); err != nil {
-			return nil, err
-		}
+	attrs := []any{"activity_id", activity.ID, "type", activity.Type, "items", len(activityItems)}
+	if activity.InvoiceNumber != nil {
+		attrs = append(attrs, "invoice_number", *activity.InvoiceNumber)
 	}
-
+	slog.InfoContext(ctx, "activity created", attrs...)
 	return &activity, nil
 }
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+const (
+	apiKeyPrefix = "bk_"
+	// apiKeyTouchInterval limits last-used writes to one per key per interval
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyForbiddenScopes cannot be granted to keys, so a leaked key can never
+// create accounts or hand out more access
+var apiKeyForbiddenScopes = []string{models.PermUserManage, models.PermRoleManage}
+
+type APIKeyService interface {
+	GetAll(ctx context.Context, page, limit int) ([]models.APIKey, int64, error)
+	Create(ctx context.Context, actorID uint, form *forms.APIKeyForm) (*models.APIKey, string, error)
+	Revoke(ctx context.Context, id uint) error
+	Authenticate(ctx context.Context, rawKey, ip string) (*utils.UserClaims, error)
+}
+
+type apiKeyService struct {
+	repo              repository.APIKeyRepository
+	userRepo          repository.UserRepository
+	permissionService PermissionService
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository, permissionService PermissionService) APIKeyService {
+	return &apiKeyService{repo, userRepo, permissionService}
+}
+
+func (s *apiKeyService) GetAll(ctx context.Context, page, limit int) ([]models.APIKey, int64, error) {
+	return s.repo.WithContext(ctx).FindAll(page, limit)
+}
+
+// Create stores a new key and returns it together with the raw key, which is
+// shown only once
+func (s *apiKeyService) Create(ctx context.Context, actorID uint, form *forms.APIKeyForm) (*models.APIKey, string, error) {
+	scopes, err := normalizePermissions(form.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if contains(apiKeyForbiddenScopes, scope) {
+			return nil, "", apperror.Validation("scope_not_allowed", "scope cannot be granted to api keys: "+scope)
+		}
+	}
+
+	if form.ExpiresAt != nil && !form.ExpiresAt.After(time.Now()) {
+		return nil, "", apperror.Validation("invalid_expiry", "expiry must be in the future")
+	}
+
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		Name:        form.Name,
+		Prefix:      rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:     utils.HashToken(rawKey),
+		AllowedIPs:  strings.Join(form.AllowedIPs, ","),
+		ExpiresAt:   form.ExpiresAt,
+		CreatedByID: actorID,
+		CreatedAt:   time.Now(),
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, models.APIKeyScope{Permission: scope})
+	}
+	if err := s.repo.WithContext(ctx).Create(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id uint) error {
+	key, err := s.repo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return apperror.NotFound("api_key_not_found", "api key not found")
+	}
+	return s.repo.WithContext(ctx).Revoke(id)
+}
+
+// Authenticate resolves a raw key to the claims of its creator. The key stops
+// working once its creator is deactivated, and its scopes are limited to what
+// the creator's role grants now, so a demoted creator's keys lose access too.
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey, ip string) (*utils.UserClaims, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, apperror.Unauthorized("invalid_api_key", "invalid api key")
+	}
+
+	key, err := s.repo.WithContext(ctx).FindByHash(utils.HashToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, apperror.Unauthorized("invalid_api_key", "invalid api key")
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, apperror.Unauthorized("api_key_expired", "api key has expired")
+	}
+	if !ipAllowed(key.AllowedIPs, ip) {
+		return nil, apperror.Unauthorized("api_key_ip_not_allowed", "api key is not allowed from this address")
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != ip {
+		if err := s.repo.WithContext(ctx).TouchLastUsed(key.ID, ip); err != nil {
+			return nil, err
+		}
+	}
+
+	creator, err := s.userRepo.WithContext(ctx).FindByID(key.CreatedByID)
+	if err != nil {
+		return nil, err
+	}
+	if creator == nil || !creator.IsActive {
+		return nil, apperror.Unauthorized("api_key_owner_inactive", "the owner of this api key is no longer active")
+	}
+
+	claims := &utils.UserClaims{ID: creator.ID, Email: creator.Email, Role: creator.Role, APIKeyID: key.ID}
+	for _, scope := range key.Scopes {
+		granted, err := s.permissionService.HasPermissions(ctx, creator.Role, scope.Permission)
+		if err != nil {
+			return nil, err
+		}
+		if granted {
+			claims.Scopes = append(claims.Scopes, scope.Permission)
+		}
+	}
+	return claims, nil
+}
+
+// ipAllowed checks an IP against a comma separated list of IPs and CIDRs
+func ipAllowed(allowlist, ip string) bool {
+	if allowlist == "" {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
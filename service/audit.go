@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+// auditMaxSnapshot caps the size of a stored before/after snapshot
+const auditMaxSnapshot = 16 * 1024
+
+// auditRedactedFields never reach the audit log, wherever they are nested
+var auditRedactedFields = map[string]bool{
+	"password":           true,
+	"temporary_password": true,
+	"token":              true,
+	"refresh_token":      true,
+	"challenge_token":    true,
+	"key":                true,
+	"secret":             true,
+	"uri":                true,
+	"qr_code":            true,
+	"recovery_codes":     true,
+}
+
+// auditIgnoredFields change on every write and would only add noise to the diff
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+type AuditService interface {
+	Record(ctx context.Context, entry *models.AuditLog, before, after interface{}) error
+	Search(ctx context.Context, query forms.AuditLogQueryForm, page, limit int) ([]models.AuditLog, int64, error)
+}
+
+type auditService struct {
+	repo repository.AuditLogRepository
+}
+
+func NewAuditService(repo repository.AuditLogRepository) AuditService {
+	return &auditService{repo}
+}
+
+// Record stores an audit entry with redacted snapshots of the entity before
+// and after the change and the fields that differ between them
+func (s *auditService) Record(ctx context.Context, entry *models.AuditLog, before, after interface{}) error {
+	b, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	a, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	if entry.EntityID == "" {
+		entry.EntityID = auditEntityID(a)
+	}
+	entry.Before = auditJSON(b)
+	entry.After = auditJSON(a)
+	if changes := auditDiff(b, a); len(changes) > 0 {
+		entry.Changes = auditJSON(changes)
+	}
+
+	return s.repo.WithContext(ctx).Create(entry)
+}
+
+// Search filters the audit log; the from and to dates are both inclusive
+func (s *auditService) Search(ctx context.Context, query forms.AuditLogQueryForm, page, limit int) ([]models.AuditLog, int64, error) {
+	filter := repository.AuditLogFilter{
+		Entity:   query.Entity,
+		EntityID: query.EntityID,
+		Action:   query.Action,
+		ActorID:  query.UserID,
+	}
+	if query.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", query.From, time.Local)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", query.To, time.Local)
+		if err != nil {
+			return nil, 0, err
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	return s.repo.WithContext(ctx).FindAll(filter, page, limit)
+}
+
+// auditSnapshot turns a model, a response body (json.RawMessage) or nil into
+// generic JSON values with secrets removed. Responses wrapped in {"data": ...}
+// are unwrapped.
+func auditSnapshot(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	var snapshot interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		// Not JSON, e.g. a file download
+		return nil, nil
+	}
+	if m, ok := snapshot.(map[string]interface{}); ok {
+		if data, ok := m["data"].(map[string]interface{}); ok {
+			snapshot = data
+		}
+	}
+	return redact(snapshot), nil
+}
+
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if auditRedactedFields[strings.ToLower(k)] {
+				value[k] = "[redacted]"
+			} else {
+				value[k] = redact(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redact(child)
+		}
+	}
+	return v
+}
+
+// auditDiff lists the top-level fields that changed as {"field": {"from": x, "to": y}}
+func auditDiff(before, after interface{}) map[string]interface{} {
+	b, okBefore := before.(map[string]interface{})
+	a, okAfter := after.(map[string]interface{})
+	if !okBefore || !okAfter {
+		return nil
+	}
+
+	changes := make(map[string]interface{})
+	for k, from := range b {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		if to, ok := a[k]; ok && !reflect.DeepEqual(from, to) {
+			changes[k] = map[string]interface{}{"from": from, "to": to}
+		}
+	}
+	for k, to := range a {
+		if _, ok := b[k]; !ok && !auditIgnoredFields[k] {
+			changes[k] = map[string]interface{}{"from": nil, "to": to}
+		}
+	}
+	return changes
+}
+
+// auditEntityID takes the ID of a created entity from its snapshot
+func auditEntityID(snapshot interface{}) string {
+	m, ok := snapshot.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	switch id := m["id"].(type) {
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	case string:
+		return id
+	}
+	return ""
+}
+
+func auditJSON(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	if len(b) > auditMaxSnapshot {
+		return `{"truncated":true}`
+	}
+	return string(b)
+}
@@ -1,18 +1,24 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"sort"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/repository"
 )
 
 type CategoryService interface {
-	GetAll(page, limit int) ([]models.Category, int64, error)
-	GetByID(id uint) (*models.Category, error)
-	Create(form *forms.CategoryForm) (*models.Category, error)
-	Update(id uint, form *forms.CategoryForm) (*models.Category, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context, page, limit int) ([]models.Category, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.Category, error)
+	GetTree(ctx context.Context, rootID uint) ([]models.Category, error)
+	Create(ctx context.Context, form *forms.CategoryForm) (*models.Category, error)
+	Update(ctx context.Context, id uint, form *forms.CategoryForm) (*models.Category, error)
+	Move(ctx context.Context, id uint, parentID *uint) (*models.Category, error)
+	Delete(ctx context.Context, id uint) error
+	GetSalesRollup(ctx context.Context, level int, rootID uint) ([]models.CategorySales, error)
 }
 
 type categoryService struct {
@@ -23,46 +29,226 @@ func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryServ
 	return &categoryService{categoryRepo}
 }
 
-func (s *categoryService) GetAll(page, limit int) ([]models.Category, int64, error) {
-	return s.categoryRepo.FindAll(page, limit)
+func (s *categoryService) GetAll(ctx context.Context, page, limit int) ([]models.Category, int64, error) {
+	return s.categoryRepo.WithContext(ctx).FindAll(page, limit)
 }
 
-func (s *categoryService) GetByID(id uint) (*models.Category, error) {
-	category, err := s.categoryRepo.FindByID(id)
+func (s *categoryService) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	category, err := s.categoryRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 	if category == nil {
-		return nil, errors.New("category not found")
+		return nil, apperror.NotFound("category_not_found", "category not found")
 	}
 	return category, nil
 }
 
-func (s *categoryService) Create(form *forms.CategoryForm) (*models.Category, error) {
+// GetTree returns the top-level categories with their subcategories, or only
+// the subtree below rootID when it is not 0
+func (s *categoryService) GetTree(ctx context.Context, rootID uint) ([]models.Category, error) {
+	tree, err := loadCategoryTree(s.categoryRepo.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if rootID > 0 {
+		if _, ok := tree.byID[rootID]; !ok {
+			return nil, apperror.NotFound("category_not_found", "category not found")
+		}
+		return []models.Category{tree.build(rootID)}, nil
+	}
+
+	roots := []models.Category{}
+	for _, id := range tree.roots {
+		roots = append(roots, tree.build(id))
+	}
+	return roots, nil
+}
+
+func (s *categoryService) Create(ctx context.Context, form *forms.CategoryForm) (*models.Category, error) {
+	if form.ParentID != nil {
+		parent, err := s.categoryRepo.WithContext(ctx).FindByID(*form.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, apperror.Validation("parent_category_not_found", "parent category not found")
+		}
+	}
+
 	category := &models.Category{
-		Name: form.Name,
+		Name:     form.Name,
+		ParentID: form.ParentID,
 	}
-	if err := s.categoryRepo.Create(category); err != nil {
+	if err := s.categoryRepo.WithContext(ctx).Create(category); err != nil {
 		return nil, err
 	}
 	return category, nil
 }
 
-func (s *categoryService) Update(id uint, form *forms.CategoryForm) (*models.Category, error) {
-	category, err := s.categoryRepo.FindByID(id)
+// Update renames a category, and moves it when a parent is given
+func (s *categoryService) Update(ctx context.Context, id uint, form *forms.CategoryForm) (*models.Category, error) {
+	category, err := s.categoryRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 	if category == nil {
-		return nil, errors.New("category not found")
+		return nil, apperror.NotFound("category_not_found", "category not found")
+	}
+
+	if form.ParentID != nil {
+		if err := s.checkMove(ctx, id, form.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = form.ParentID
 	}
+
 	category.Name = form.Name
-	if err := s.categoryRepo.Update(category); err != nil {
+	if err := s.categoryRepo.WithContext(ctx).Update(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Move puts a category under another parent, or at the top level when parentID is nil
+func (s *categoryService) Move(ctx context.Context, id uint, parentID *uint) (*models.Category, error) {
+	category, err := s.categoryRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, apperror.NotFound("category_not_found", "category not found")
+	}
+
+	if err := s.checkMove(ctx, id, parentID); err != nil {
+		return nil, err
+	}
+
+	category.ParentID = parentID
+	if err := s.categoryRepo.WithContext(ctx).Update(category); err != nil {
 		return nil, err
 	}
 	return category, nil
 }
 
-func (s *categoryService) Delete(id uint) error {
-	return s.categoryRepo.Delete(id)
+// checkMove refuses parents that do not exist or would create a cycle
+func (s *categoryService) checkMove(ctx context.Context, id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+
+	tree, err := loadCategoryTree(s.categoryRepo.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if _, ok := tree.byID[*parentID]; !ok {
+		return apperror.Validation("parent_category_not_found", "parent category not found")
+	}
+	for _, d := range tree.descendants(id) {
+		if d == *parentID {
+			return apperror.Validation("category_cycle", "category cannot be moved under itself")
+		}
+	}
+	return nil
+}
+
+// Delete removes an empty category. Products and subcategories must be moved first.
+func (s *categoryService) Delete(ctx context.Context, id uint) error {
+	category, err := s.categoryRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return apperror.NotFound("category_not_found", "category not found")
+	}
+
+	children, err := s.categoryRepo.WithContext(ctx).CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return apperror.Conflict("category_has_children", "category has subcategories")
+	}
+
+	products, err := s.categoryRepo.WithContext(ctx).CountProducts(id)
+	if err != nil {
+		return err
+	}
+	if products > 0 {
+		return apperror.Conflict("category_has_products", "category has products")
+	}
+
+	return s.categoryRepo.WithContext(ctx).Delete(id)
+}
+
+// GetSalesRollup sums the sales of every category up to the given tree level
+// (0 = top-level categories). Categories shallower than the level are reported
+// with their own products only. When rootID is not 0 only its subtree is used.
+func (s *categoryService) GetSalesRollup(ctx context.Context, level int, rootID uint) ([]models.CategorySales, error) {
+	tree, err := loadCategoryTree(s.categoryRepo.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	included := make(map[uint]bool)
+	if rootID > 0 {
+		if _, ok := tree.byID[rootID]; !ok {
+			return nil, apperror.NotFound("category_not_found", "category not found")
+		}
+		for _, id := range tree.descendants(rootID) {
+			included[id] = true
+		}
+		// Never roll up above the requested root
+		if rootLevel := len(tree.ancestors(rootID)) - 1; level < rootLevel {
+			level = rootLevel
+		}
+	}
+
+	sales, err := s.categoryRepo.WithContext(ctx).SalesPerCategory()
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := make(map[uint]*models.CategorySales)
+	for _, row := range sales {
+		if rootID > 0 && !included[row.ID] {
+			continue
+		}
+
+		chain := tree.ancestors(row.ID)
+		if len(chain) == 0 {
+			continue
+		}
+		target := chain[len(chain)-1]
+		if len(chain) > level+1 {
+			target = chain[level]
+		}
+
+		r, ok := rollup[target]
+		if !ok {
+			r = &models.CategorySales{
+				ID:    target,
+				Name:  tree.byID[target].Name,
+				Path:  tree.path(target),
+				Level: len(tree.ancestors(target)) - 1,
+			}
+			rollup[target] = r
+		}
+		r.Products += row.Products
+		r.Stock += row.Stock
+		r.TotalSales += row.TotalSales
+	}
+
+	result := make([]models.CategorySales, 0, len(rollup))
+	for _, r := range rollup {
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalSales != result[j].TotalSales {
+			return result[i].TotalSales > result[j].TotalSales
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
 }
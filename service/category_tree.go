@@ -0,0 +1,84 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+// categoryTree is an in-memory index of the category hierarchy
+type categoryTree struct {
+	byID     map[uint]models.Category
+	children map[uint][]uint
+	roots    []uint
+}
+
+func loadCategoryTree(repo repository.CategoryRepository) (*categoryTree, error) {
+	categories, err := repo.FindAllFlat()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &categoryTree{
+		byID:     make(map[uint]models.Category, len(categories)),
+		children: make(map[uint][]uint),
+	}
+	for _, c := range categories {
+		t.byID[c.ID] = c
+	}
+	for _, c := range categories {
+		// Treat categories whose parent is gone as roots
+		if c.ParentID == nil || t.byID[*c.ParentID].ID == 0 {
+			t.roots = append(t.roots, c.ID)
+			continue
+		}
+		t.children[*c.ParentID] = append(t.children[*c.ParentID], c.ID)
+	}
+	return t, nil
+}
+
+// descendants returns id followed by all categories below it
+func (t *categoryTree) descendants(id uint) []uint {
+	ids := []uint{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, t.children[ids[i]]...)
+	}
+	return ids
+}
+
+// build returns the category with its children filled in recursively
+func (t *categoryTree) build(id uint) models.Category {
+	c := t.byID[id]
+	c.Children = []models.Category{}
+	for _, child := range t.children[id] {
+		c.Children = append(c.Children, t.build(child))
+	}
+	return c
+}
+
+// ancestors returns the chain from the top-level category down to id
+func (t *categoryTree) ancestors(id uint) []uint {
+	var chain []uint
+	seen := make(map[uint]bool)
+	for current, ok := t.byID[id]; ok && !seen[current.ID]; current, ok = t.byID[derefUint(current.ParentID)] {
+		seen[current.ID] = true
+		chain = append([]uint{current.ID}, chain...)
+	}
+	return chain
+}
+
+func (t *categoryTree) path(id uint) string {
+	var names []string
+	for _, a := range t.ancestors(id) {
+		names = append(names, t.byID[a].Name)
+	}
+	return strings.Join(names, " / ")
+}
+
+func derefUint(p *uint) uint {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
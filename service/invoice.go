@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+type InvoiceService interface {
+	RenderPDF(ctx context.Context, activityID uint) ([]byte, string, error)
+}
+
+type invoiceService struct {
+	activityRepo   repository.ActivityRepository
+	receiptService ReceiptService
+}
+
+func NewInvoiceService(activityRepo repository.ActivityRepository, receiptService ReceiptService) InvoiceService {
+	return &invoiceService{activityRepo, receiptService}
+}
+
+// RenderPDF returns the invoice PDF of a sale together with its invoice number
+func (s *invoiceService) RenderPDF(ctx context.Context, activityID uint) ([]byte, string, error) {
+	activity, err := s.activityRepo.WithContext(ctx).FindByID(activityID)
+	if err != nil {
+		return nil, "", err
+	}
+	if activity == nil {
+		return nil, "", apperror.NotFound("activity_not_found", "activity not found")
+	}
+	if activity.InvoiceNumber == nil {
+		return nil, "", apperror.NotFound("invoice_not_found", "activity has no invoice")
+	}
+
+	template, err := s.receiptService.GetTemplate(ctx, activity.Branch)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(110, 8, tr(template.WorkshopName), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(70, 8, "INVOICE", "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(110, 5, tr(template.Address), "", 0, "L", false, 0, "")
+	pdf.CellFormat(70, 5, *activity.InvoiceNumber, "", 1, "R", false, 0, "")
+	phone := ""
+	if template.Phone != "" {
+		phone = "Telp. " + template.Phone
+	}
+	pdf.CellFormat(110, 5, tr(phone), "", 0, "L", false, 0, "")
+	pdf.CellFormat(70, 5, "Tanggal: "+activity.Date.Format("02/01/2006"), "", 1, "R", false, 0, "")
+	pdf.Ln(6)
+
+	// Customer & vehicle
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(90, 6, "Pelanggan", "", 0, "L", false, 0, "")
+	pdf.CellFormat(90, 6, "Kendaraan", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(90, 6, tr(orDash(activity.CustomerName)), "", 0, "L", false, 0, "")
+	pdf.CellFormat(90, 6, tr(orDash(activity.VehiclePlate)), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Lines
+	widths := []float64{10, 80, 20, 35, 35}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for i, h := range []string{"No", "Produk", "Qty", "Harga", "Jumlah"} {
+		align := "L"
+		if i >= 2 {
+			align = "R"
+		}
+		pdf.CellFormat(widths[i], 7, h, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	var subtotal, discount float64
+	for i, item := range activity.Items {
+		gross := item.PriceAtTime * float64(item.Quantity)
+		net := item.FinalPrice * float64(item.Quantity)
+		subtotal += gross
+		discount += gross - net
+
+		pdf.CellFormat(widths[0], 7, strconv.Itoa(i+1), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, tr(item.Product.Name), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 7, strconv.Itoa(item.Quantity), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, utils.FormatRupiah(item.PriceAtTime), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, utils.FormatRupiah(gross), "1", 1, "R", false, 0, "")
+	}
+
+	// Totals
+	base := subtotal - discount
+	tax := base * activity.TaxRate / 100
+	total := base + tax
+
+	totals := [][2]string{{"Subtotal", utils.FormatRupiah(subtotal)}}
+	if discount > 0 {
+		totals = append(totals, [2]string{"Diskon", utils.FormatRupiah(-discount)})
+	}
+	if activity.TaxRate > 0 {
+		totals = append(totals, [2]string{fmt.Sprintf("PPN %s%%", strconv.FormatFloat(activity.TaxRate, 'f', -1, 64)), utils.FormatRupiah(tax)})
+	}
+	totals = append(totals, [2]string{"Total", utils.FormatRupiah(total)})
+
+	pdf.Ln(2)
+	for i, t := range totals {
+		if i == len(totals)-1 {
+			pdf.SetFont("Helvetica", "B", 11)
+		}
+		pdf.CellFormat(145, 7, t[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, t[1], "", 1, "R", false, 0, "")
+	}
+
+	// Payment status
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(180, 8, "Status pembayaran: "+paymentStatusLabel(activity.PaymentStatus), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(180, 6, tr("Kasir: "+activity.User.Name), "", 1, "L", false, 0, "")
+	if template.Footer != "" {
+		pdf.Ln(4)
+		pdf.MultiCell(180, 5, tr(template.Footer), "", "C", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), *activity.InvoiceNumber, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+// loginBackoffBase is the delay after the first failed login; it doubles with every further failure
+const loginBackoffBase = time.Second
+
+// LoginThrottle is the detail of the error returned while an account or IP
+// has to wait before trying again
+type LoginThrottle struct {
+	Locked     bool `json:"locked"`
+	RetryAfter int  `json:"retry_after"`
+}
+
+// LoginGuardService throttles failed logins per account and per IP and keeps
+// the login audit trail.
+type LoginGuardService interface {
+	Check(ctx context.Context, user *models.User, email, ip, userAgent string) error
+	RecordFailure(ctx context.Context, user *models.User, email, ip, userAgent, reason string) error
+	Release(ctx context.Context, email, ip string) error
+	RecordSuccess(ctx context.Context, user *models.User, ip, userAgent string) error
+	Unlock(ctx context.Context, email string) error
+	GetHistory(ctx context.Context, userID uint, page, limit int) ([]models.LoginAttempt, int64, error)
+}
+
+type loginGuardService struct {
+	repo repository.LoginAttemptRepository
+	cfg  config.Login
+}
+
+func NewLoginGuardService(repo repository.LoginAttemptRepository, cfg config.Login) LoginGuardService {
+	return &loginGuardService{repo, cfg}
+}
+
+// Check refuses the attempt while the account or the IP is locked or still
+// backing off. Otherwise the attempt is counted as a failure of both up front,
+// with their throttles locked, so parallel attempts already see it and back
+// off; RecordSuccess and Release take it back once the password is right.
+// Refused attempts are logged but do not count as failures.
+func (s *loginGuardService) Check(ctx context.Context, user *models.User, email, ip, userAgent string) error {
+	now := time.Now()
+	keys := []repository.ThrottleKey{
+		{Kind: models.ThrottleAccount, Identifier: normalizeEmail(email)},
+		{Kind: models.ThrottleIP, Identifier: ip},
+	}
+	limits := []int{s.cfg.MaxAttempts, s.cfg.MaxAttemptsPerIP}
+
+	var retryAfter time.Duration
+	var locked bool
+	err := s.repo.WithContext(ctx).UpdateThrottles(keys, func(throttles []*models.LoginThrottle) error {
+		for i, throttle := range throttles {
+			// Start over once the previous failures or lock have expired
+			expired := now.Sub(throttle.LastFailedAt) > s.cfg.LockoutDuration
+			if throttle.LockedUntil != nil {
+				expired = now.After(*throttle.LockedUntil)
+			}
+			if expired {
+				throttle.Failures = 0
+				throttle.LockedUntil = nil
+			}
+
+			if throttle.LockedUntil == nil && throttle.Failures >= limits[i] {
+				lockedUntil := now.Add(s.cfg.LockoutDuration)
+				throttle.LockedUntil = &lockedUntil
+			}
+			if throttle.LockedUntil != nil {
+				retryAfter, locked = throttle.LockedUntil.Sub(now), true
+				return nil
+			}
+			if throttle.Kind == models.ThrottleAccount {
+				if wait := throttle.LastFailedAt.Add(s.loginBackoff(throttle.Failures)).Sub(now); wait > 0 {
+					retryAfter = wait
+					return nil
+				}
+			}
+		}
+
+		for _, throttle := range throttles {
+			throttle.Failures++
+			throttle.LastFailedAt = now
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return s.refuse(ctx, user, email, ip, userAgent, retryAfter, locked)
+	}
+	return nil
+}
+
+func (s *loginGuardService) refuse(ctx context.Context, user *models.User, email, ip, userAgent string, retryAfter time.Duration, locked bool) error {
+	reason := "backoff"
+	if locked {
+		reason = "locked"
+	}
+	if err := s.log(ctx, user, email, ip, userAgent, false, reason); err != nil {
+		return err
+	}
+	return apperror.TooManyRequests("login_throttled", "too many login attempts", retryAfter, LoginThrottle{
+		Locked:     locked,
+		RetryAfter: int(math.Ceil(retryAfter.Seconds())),
+	})
+}
+
+// RecordFailure logs a failed attempt; Check has already counted it against
+// the account and the IP. user is nil when the email is unknown; the account
+// is throttled all the same so responses do not reveal which emails exist.
+func (s *loginGuardService) RecordFailure(ctx context.Context, user *models.User, email, ip, userAgent, reason string) error {
+	return s.log(ctx, user, email, ip, userAgent, false, reason)
+}
+
+// Release takes back the failure Check counted for an attempt whose password
+// was right but which still has to pass the second factor. The account's
+// earlier failures stay until the login succeeds.
+func (s *loginGuardService) Release(ctx context.Context, email, ip string) error {
+	if err := s.repo.WithContext(ctx).ReleaseThrottle(models.ThrottleAccount, normalizeEmail(email)); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).ReleaseThrottle(models.ThrottleIP, ip)
+}
+
+// RecordSuccess logs a successful login, clears the account's failures and
+// takes back the failure Check counted against the IP
+func (s *loginGuardService) RecordSuccess(ctx context.Context, user *models.User, ip, userAgent string) error {
+	if err := s.log(ctx, user, user.Email, ip, userAgent, true, ""); err != nil {
+		return err
+	}
+	if err := s.repo.WithContext(ctx).DeleteThrottle(models.ThrottleAccount, normalizeEmail(user.Email)); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).ReleaseThrottle(models.ThrottleIP, ip)
+}
+
+// Unlock clears the failures and lock of an account
+func (s *loginGuardService) Unlock(ctx context.Context, email string) error {
+	return s.repo.WithContext(ctx).DeleteThrottle(models.ThrottleAccount, normalizeEmail(email))
+}
+
+func (s *loginGuardService) GetHistory(ctx context.Context, userID uint, page, limit int) ([]models.LoginAttempt, int64, error) {
+	return s.repo.WithContext(ctx).FindByUserID(userID, page, limit)
+}
+
+func (s *loginGuardService) log(ctx context.Context, user *models.User, email, ip, userAgent string, success bool, reason string) error {
+	attempt := &models.LoginAttempt{
+		Email:     normalizeEmail(email),
+		IP:        ip,
+		UserAgent: truncate(userAgent, 255),
+		Success:   success,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	return s.repo.WithContext(ctx).Create(attempt)
+}
+
+// loginBackoff is the wait after the given number of consecutive failures: 1s, 2s, 4s, ...
+func (s *loginGuardService) loginBackoff(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	backoff := loginBackoffBase << (failures - 1)
+	if lockout := s.cfg.LockoutDuration; backoff > lockout || backoff <= 0 {
+		return lockout
+	}
+	return backoff
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// passwordResetTTL is how long an emailed reset link stays valid. It is also
+// the window in which reset requests are limited.
+const passwordResetTTL = time.Hour
+
+type PasswordService interface {
+	ChangePassword(ctx context.Context, userID uint, sessionID string, form *forms.ChangePasswordForm) error
+	RequestReset(ctx context.Context, email, ip string) error
+	SendResetLink(ctx context.Context, actorID, userID uint) error
+	ResetPassword(ctx context.Context, form *forms.ResetPasswordForm) (*models.User, error)
+}
+
+type passwordService struct {
+	userRepo       repository.UserRepository
+	resetRepo      repository.PasswordResetTokenRepository
+	auditRepo      repository.UserAuditLogRepository
+	throttleRepo   repository.LoginAttemptRepository
+	sessionService SessionService
+	mailer         utils.Mailer
+	cfg            config.Login
+}
+
+func NewPasswordService(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetTokenRepository,
+	auditRepo repository.UserAuditLogRepository,
+	throttleRepo repository.LoginAttemptRepository,
+	sessionService SessionService,
+	mailer utils.Mailer,
+	cfg config.Login,
+) PasswordService {
+	return &passwordService{userRepo, resetRepo, auditRepo, throttleRepo, sessionService, mailer, cfg}
+}
+
+// ChangePassword lets a logged-in user pick a new password. Other sessions are
+// logged out; the current one stays valid.
+func (s *passwordService) ChangePassword(ctx context.Context, userID uint, sessionID string, form *forms.ChangePasswordForm) error {
+	user, err := s.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return apperror.NotFound("user_not_found", "user not found")
+	}
+
+	if !utils.CheckHash(form.OldPassword, user.Password) {
+		return apperror.Validation("incorrect_password", "old password is incorrect")
+	}
+
+	if err := s.setPassword(ctx, user, form.NewPassword); err != nil {
+		return err
+	}
+	return s.sessionService.LogoutOthers(ctx, user.ID, sessionID)
+}
+
+// RequestReset emails a reset link. It never reveals whether the email exists:
+// unknown emails are limited the same way, and a link that cannot be sent is
+// only logged. Each email and IP may request a limited number of links per hour.
+func (s *passwordService) RequestReset(ctx context.Context, email, ip string) error {
+	if err := s.limitResetRequests(ctx, email, ip); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.WithContext(ctx).FindUserByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+	if err := s.sendResetLink(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "sending the password reset link failed", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// limitResetRequests counts a reset request against the email and the IP and
+// refuses it once either has used up its requests. The count starts over an
+// hour after the last request.
+func (s *passwordService) limitResetRequests(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	keys := []repository.ThrottleKey{
+		{Kind: models.ThrottleResetAccount, Identifier: normalizeEmail(email)},
+		{Kind: models.ThrottleResetIP, Identifier: ip},
+	}
+	limits := []int{s.cfg.MaxResetRequests, s.cfg.MaxResetRequestsPerIP}
+
+	var retryAfter time.Duration
+	err := s.throttleRepo.WithContext(ctx).UpdateThrottles(keys, func(throttles []*models.LoginThrottle) error {
+		for i, throttle := range throttles {
+			if now.Sub(throttle.LastFailedAt) > passwordResetTTL {
+				throttle.Failures = 0
+			}
+			if throttle.Failures >= limits[i] {
+				retryAfter = throttle.LastFailedAt.Add(passwordResetTTL).Sub(now)
+				return nil
+			}
+		}
+		for _, throttle := range throttles {
+			throttle.Failures++
+			throttle.LastFailedAt = now
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return apperror.TooManyRequests("reset_throttled", "too many password reset requests", retryAfter, nil)
+	}
+	return nil
+}
+
+// SendResetLink is the admin-initiated variant of RequestReset
+func (s *passwordService) SendResetLink(ctx context.Context, actorID, userID uint) error {
+	user, err := s.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return apperror.NotFound("user_not_found", "user not found")
+	}
+
+	if err := s.sendResetLink(ctx, user); err != nil {
+		return err
+	}
+
+	return s.auditRepo.WithContext(ctx).Create(&models.UserAuditLog{
+		ActorID:      actorID,
+		TargetUserID: user.ID,
+		Action:       "send_password_reset",
+		CreatedAt:    time.Now(),
+	})
+}
+
+// ResetPassword redeems a reset token, logs the user out everywhere and returns the user
+func (s *passwordService) ResetPassword(ctx context.Context, form *forms.ResetPasswordForm) (*models.User, error) {
+	token, err := s.resetRepo.WithContext(ctx).FindByHash(utils.HashToken(form.Token))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, apperror.Validation("invalid_reset_token", "invalid or expired reset token")
+	}
+
+	if err := s.resetRepo.WithContext(ctx).MarkUsed(token.ID); err != nil {
+		if apperror.HasCode(err, "reset_token_used") {
+			return nil, apperror.Validation("invalid_reset_token", "invalid or expired reset token")
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.WithContext(ctx).FindByID(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, apperror.Validation("invalid_reset_token", "invalid or expired reset token")
+	}
+
+	if err := s.setPassword(ctx, user, form.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *passwordService) sendResetLink(ctx context.Context, user *models.User) error {
+	// Only the newest link works
+	if err := s.resetRepo.WithContext(ctx).InvalidateForUser(user.ID); err != nil {
+		return err
+	}
+
+	raw, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := s.resetRepo.WithContext(ctx).Create(token); err != nil {
+		return err
+	}
+
+	link := s.cfg.PasswordResetURL
+	body := fmt.Sprintf("Halo %s,\n\nGunakan kode berikut untuk mengatur ulang password Anda:\n\n%s\n", user.Name, raw)
+	if link != "" {
+		body += fmt.Sprintf("\nAtau buka tautan ini: %s?token=%s\n", link, raw)
+	}
+	body += fmt.Sprintf("\nKode berlaku selama %d menit. Abaikan email ini jika Anda tidak memintanya.\n", int(passwordResetTTL.Minutes()))
+
+	return s.mailer.Send(user.Email, "Reset password", body)
+}
+
+func (s *passwordService) setPassword(ctx context.Context, user *models.User, password string) error {
+	hashedPassword, err := utils.GenerateHash(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.MustResetPassword = false
+	user.UpdatedAt = time.Now()
+	return s.userRepo.WithContext(ctx).Update(user)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// permissionCacheTTL bounds how long a role edit made by another instance takes to apply
+const permissionCacheTTL = 30 * time.Second
+
+type PermissionService interface {
+	HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error)
+	Allows(ctx context.Context, caller *utils.UserClaims, permissions ...string) (bool, error)
+	GetRoles(ctx context.Context) ([]models.Role, error)
+	CreateRole(ctx context.Context, form *forms.RoleForm) (*models.Role, error)
+	UpdateRolePermissions(ctx context.Context, name string, permissions []string) (*models.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	SetTwoFactorRequired(ctx context.Context, name string, required bool) (*models.Role, error)
+	RequiresTwoFactor(ctx context.Context, role string) (bool, error)
+}
+
+type permissionService struct {
+	roleRepo repository.RoleRepository
+
+	mu       sync.RWMutex
+	cache    map[string]map[string]bool
+	loadedAt time.Time
+}
+
+func NewPermissionService(roleRepo repository.RoleRepository) PermissionService {
+	return &permissionService{roleRepo: roleRepo}
+}
+
+// HasPermissions reports whether the role grants every given permission
+func (s *permissionService) HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error) {
+	granted, err := s.rolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if !granted[role][p] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Allows reports whether the caller may use every given permission. API keys
+// are limited to their own scopes, users to the permissions of their role.
+func (s *permissionService) Allows(ctx context.Context, caller *utils.UserClaims, permissions ...string) (bool, error) {
+	if caller.APIKeyID != 0 {
+		for _, p := range permissions {
+			if !contains(caller.Scopes, p) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return s.HasPermissions(ctx, caller.Role, permissions...)
+}
+
+func (s *permissionService) rolePermissions(ctx context.Context) (map[string]map[string]bool, error) {
+	s.mu.RLock()
+	cache, fresh := s.cache, time.Since(s.loadedAt) < permissionCacheTTL
+	s.mu.RUnlock()
+	if cache != nil && fresh {
+		return cache, nil
+	}
+
+	roles, err := s.roleRepo.WithContext(ctx).FindAll()
+	if err != nil {
+		return nil, err
+	}
+	cache = make(map[string]map[string]bool, len(roles))
+	for _, r := range roles {
+		cache[r.Name] = make(map[string]bool, len(r.Permissions))
+		for _, p := range r.Permissions {
+			cache[r.Name][p.Permission] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.cache, s.loadedAt = cache, time.Now()
+	s.mu.Unlock()
+	return cache, nil
+}
+
+func (s *permissionService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func (s *permissionService) GetRoles(ctx context.Context) ([]models.Role, error) {
+	return s.roleRepo.WithContext(ctx).FindAll()
+}
+
+func (s *permissionService) CreateRole(ctx context.Context, form *forms.RoleForm) (*models.Role, error) {
+	existing, err := s.roleRepo.WithContext(ctx).FindByName(form.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperror.Conflict("role_exists", "role already exists")
+	}
+
+	permissions, err := normalizePermissions(form.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        form.Name,
+		Description: form.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	for _, p := range permissions {
+		role.Permissions = append(role.Permissions, models.RolePermission{Permission: p})
+	}
+	if err := s.roleRepo.WithContext(ctx).Create(role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+func (s *permissionService) UpdateRolePermissions(ctx context.Context, name string, permissions []string) (*models.Role, error) {
+	role, err := s.roleRepo.WithContext(ctx).FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, apperror.NotFound("role_not_found", "role not found")
+	}
+
+	permissions, err = normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins must always be able to fix roles again
+	if name == models.RoleAdmin && !contains(permissions, models.PermRoleManage) {
+		return nil, apperror.Validation("admin_role_locked", "admin role must keep role.manage")
+	}
+
+	if err := s.roleRepo.WithContext(ctx).ReplacePermissions(role, permissions); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+func (s *permissionService) DeleteRole(ctx context.Context, name string) error {
+	if name == models.RoleAdmin || name == models.RoleKaryawan {
+		return apperror.Conflict("builtin_role", "built-in roles cannot be deleted")
+	}
+
+	role, err := s.roleRepo.WithContext(ctx).FindByName(name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return apperror.NotFound("role_not_found", "role not found")
+	}
+
+	users, err := s.roleRepo.WithContext(ctx).CountUsers(name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return apperror.Conflict("role_in_use", "role is assigned to users")
+	}
+
+	if err := s.roleRepo.WithContext(ctx).Delete(role); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// SetTwoFactorRequired makes TOTP mandatory, or optional again, for every user of a role
+func (s *permissionService) SetTwoFactorRequired(ctx context.Context, name string, required bool) (*models.Role, error) {
+	role, err := s.roleRepo.WithContext(ctx).FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, apperror.NotFound("role_not_found", "role not found")
+	}
+
+	if err := s.roleRepo.WithContext(ctx).SetRequireTwoFactor(role, required); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// RequiresTwoFactor reports whether users of the role must log in with TOTP
+func (s *permissionService) RequiresTwoFactor(ctx context.Context, role string) (bool, error) {
+	r, err := s.roleRepo.WithContext(ctx).FindByName(role)
+	if err != nil {
+		return false, err
+	}
+	return r != nil && r.RequireTwoFactor, nil
+}
+
+// normalizePermissions rejects unknown permissions and removes duplicates
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, p := range permissions {
+		if !contains(models.AllPermissions, p) {
+			return nil, apperror.Validation("unknown_permission", "unknown permission "+p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/health"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+// priceSchedulerBatch bounds how many due changes one scheduler run applies
+const priceSchedulerBatch = 100
+
+type PriceService interface {
+	GetHistory(ctx context.Context, productID uint, page, limit int) ([]models.PriceHistory, int64, error)
+	GetScheduled(ctx context.Context, productID uint, status string) ([]models.ScheduledPriceChange, error)
+	Schedule(ctx context.Context, actorID, productID uint, form *forms.ScheduledPriceForm) (*models.ScheduledPriceChange, error)
+	CancelScheduled(ctx context.Context, id uint) error
+	ApplyDue(ctx context.Context) (int, error)
+	BulkUpdate(ctx context.Context, actorID uint, form *forms.BulkPriceForm) (*BulkPriceResult, error)
+}
+
+// BulkPriceResult lists the prices changed, or that would change on a dry run,
+// by a bulk price update. Products whose price stays the same are left out.
+type BulkPriceResult struct {
+	DryRun bool            `json:"dry_run"`
+	Count  int             `json:"count"`
+	Items  []BulkPriceItem `json:"items"`
+}
+
+type BulkPriceItem struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+type priceService struct {
+	productRepo      repository.ProductRepository
+	priceHistoryRepo repository.PriceHistoryRepository
+	scheduleRepo     repository.ScheduledPriceChangeRepository
+	categoryRepo     repository.CategoryRepository
+	supplierRepo     repository.SupplierRepository
+}
+
+func NewPriceService(
+	productRepo repository.ProductRepository,
+	priceHistoryRepo repository.PriceHistoryRepository,
+	scheduleRepo repository.ScheduledPriceChangeRepository,
+	categoryRepo repository.CategoryRepository,
+	supplierRepo repository.SupplierRepository,
+) PriceService {
+	return &priceService{productRepo, priceHistoryRepo, scheduleRepo, categoryRepo, supplierRepo}
+}
+
+func (s *priceService) GetHistory(ctx context.Context, productID uint, page, limit int) ([]models.PriceHistory, int64, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+	return s.priceHistoryRepo.WithContext(ctx).FindByProductID(productID, page, limit)
+}
+
+func (s *priceService) GetScheduled(ctx context.Context, productID uint, status string) ([]models.ScheduledPriceChange, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.WithContext(ctx).FindByProductID(productID, status)
+}
+
+// Schedule plans a price change that the scheduler applies at the effective time
+func (s *priceService) Schedule(ctx context.Context, actorID, productID uint, form *forms.ScheduledPriceForm) (*models.ScheduledPriceChange, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	if !form.EffectiveAt.After(time.Now()) {
+		return nil, apperror.Validation("effective_time_in_past", "effective time must be in the future")
+	}
+
+	change := &models.ScheduledPriceChange{
+		ProductID:   productID,
+		NewPrice:    *form.NewPrice,
+		EffectiveAt: form.EffectiveAt,
+		Status:      models.ScheduleStatusPending,
+		Note:        form.Note,
+		CreatedByID: actorID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.scheduleRepo.WithContext(ctx).Create(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *priceService) CancelScheduled(ctx context.Context, id uint) error {
+	change, err := s.scheduleRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return apperror.NotFound("scheduled_price_change_not_found", "scheduled price change not found")
+	}
+	return s.scheduleRepo.WithContext(ctx).Cancel(id)
+}
+
+// ApplyDue applies every pending change whose effective time has passed and
+// returns how many were applied. Changes of deleted products are cancelled.
+func (s *priceService) ApplyDue(ctx context.Context) (int, error) {
+	applied := 0
+	for {
+		due, err := s.scheduleRepo.WithContext(ctx).FindDue(time.Now(), priceSchedulerBatch)
+		if err != nil {
+			return applied, err
+		}
+
+		for i := range due {
+			err := s.scheduleRepo.WithContext(ctx).Apply(&due[i])
+			switch {
+			case err == nil:
+				applied++
+			case apperror.HasCode(err, "product_not_found"):
+				if err := s.scheduleRepo.WithContext(ctx).Cancel(due[i].ID); err != nil {
+					return applied, err
+				}
+			case apperror.HasCode(err, "scheduled_price_change_not_pending"):
+				// Applied or cancelled by someone else in the meantime
+			default:
+				return applied, err
+			}
+		}
+
+		if len(due) < priceSchedulerBatch {
+			return applied, nil
+		}
+	}
+}
+
+// BulkUpdate reprices every selected product at once. A dry run only returns
+// the new prices; otherwise all of them are saved in one transaction.
+func (s *priceService) BulkUpdate(ctx context.Context, actorID uint, form *forms.BulkPriceForm) (*BulkPriceResult, error) {
+	if form.CategoryID == 0 && form.SupplierID == 0 && len(form.ProductIDs) == 0 {
+		return nil, apperror.Validation("no_products_selected", "select products by category, supplier or ID")
+	}
+
+	selector := repository.PriceSelector{SupplierID: form.SupplierID, IDs: form.ProductIDs}
+	if form.SupplierID > 0 {
+		supplier, err := s.supplierRepo.WithContext(ctx).FindByID(form.SupplierID)
+		if err != nil {
+			return nil, err
+		}
+		if supplier == nil {
+			return nil, apperror.Validation("invalid_supplier", "invalid supplier ID")
+		}
+	}
+	// A category also matches the products of its subcategories
+	if form.CategoryID > 0 {
+		tree, err := loadCategoryTree(s.categoryRepo.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tree.byID[form.CategoryID]; !ok {
+			return nil, apperror.Validation("invalid_category", "invalid category ID")
+		}
+		selector.CategoryIDs = tree.descendants(form.CategoryID)
+	}
+
+	reprice := func(p models.Product) (float64, error) {
+		price := p.Price + form.Value
+		if form.Mode == "percent" {
+			price = p.Price * (1 + form.Value/100)
+		}
+		// Prices are whole rupiah
+		roundTo := float64(form.RoundTo)
+		if roundTo == 0 {
+			roundTo = 1
+		}
+		price = math.Round(price/roundTo) * roundTo
+		if price < 0 {
+			return 0, apperror.Validation("negative_price", fmt.Sprintf("price of %s would become negative", p.Name))
+		}
+		return price, nil
+	}
+
+	result := &BulkPriceResult{DryRun: form.DryRun, Items: []BulkPriceItem{}}
+	names := make(map[uint]string)
+
+	if form.DryRun {
+		products, err := s.productRepo.WithContext(ctx).FindForPricing(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			newPrice, err := reprice(p)
+			if err != nil {
+				return nil, err
+			}
+			if newPrice != p.Price {
+				result.Items = append(result.Items, BulkPriceItem{p.ID, p.Name, p.Price, newPrice})
+			}
+		}
+	} else {
+		history, err := s.productRepo.WithContext(ctx).UpdatePrices(selector, actorID, func(p models.Product) (float64, error) {
+			names[p.ID] = p.Name
+			return reprice(p)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range history {
+			result.Items = append(result.Items, BulkPriceItem{h.ProductID, names[h.ProductID], h.OldPrice, h.NewPrice})
+		}
+	}
+
+	result.Count = len(result.Items)
+	return result, nil
+}
+
+func (s *priceService) checkProduct(ctx context.Context, productID uint) error {
+	product, err := s.productRepo.WithContext(ctx).FindByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return apperror.NotFound("product_not_found", "product not found")
+	}
+	return nil
+}
+
+// RunPriceScheduler applies due price changes every interval until ctx is done,
+// recording each run on heartbeat for the readiness probe
+func RunPriceScheduler(ctx context.Context, s PriceService, interval time.Duration, heartbeat *health.Heartbeat) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := s.ApplyDue(ctx)
+		heartbeat.Record(err)
+		if err != nil {
+			slog.ErrorContext(ctx, "price scheduler failed", "error", err)
+		} else if applied > 0 {
+			slog.InfoContext(ctx, "price scheduler applied scheduled price changes", "applied", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
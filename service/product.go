@@ -1,62 +1,135 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"strconv"
 	"time"
 
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/repository"
 )
 
+// DefaultLowStockThreshold is used when low_stock is requested without a threshold
+const DefaultLowStockThreshold = 5
+
 type ProductService interface {
-	GetAll(page, limit int) ([]models.Product, int64, error)
-	Create(req forms.ProductForm) (models.Product, error)
-	GetByID(id uint) (*models.Product, error)
-	Update(id string, form forms.UpdateProductForm) (models.Product, error)
-	Delete(id string) error
-	GetSalesReport(page, limit int) ([]models.ProductSales, int64, error)
+	GetAll(ctx context.Context, query forms.ProductQueryForm, page, limit int) ([]models.Product, int64, error)
+	Create(ctx context.Context, req forms.ProductForm) (models.Product, error)
+	GetByID(ctx context.Context, id uint) (*models.Product, error)
+	Update(ctx context.Context, actorID uint, id string, form forms.UpdateProductForm) (models.Product, error)
+	Delete(ctx context.Context, id string) error
+	GetSalesReport(ctx context.Context, page, limit int) ([]models.ProductSales, int64, error)
 }
 
 // ProductService struct holds the repository instance
 type productService struct {
 	ProductRepo repository.ProductRepository
 	CategoryRepo repository.CategoryRepository
+	SupplierRepo repository.SupplierRepository
 	PriceHistoryRepo repository.PriceHistoryRepository
 }
 
 // NewProductService creates a new ProductService instance
-func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, priceHistoryRepo repository.PriceHistoryRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, supplierRepo repository.SupplierRepository, priceHistoryRepo repository.PriceHistoryRepository) ProductService {
 	return &productService{
 		ProductRepo:      productRepo,
 		CategoryRepo:     categoryRepo,
+		SupplierRepo:     supplierRepo,
 		PriceHistoryRepo: priceHistoryRepo,
 	}
 }
 
-// GetAll retrieves all products with pagination
-func (ps *productService) GetAll(page, limit int) ([]models.Product, int64, error) {
-	return ps.ProductRepo.FindAll(page, limit)
+// GetAll retrieves all products matching the query with pagination
+func (ps *productService) GetAll(ctx context.Context, query forms.ProductQueryForm, page, limit int) ([]models.Product, int64, error) {
+	filter := repository.ProductFilter{
+		Search:            query.Search,
+		Location:          query.Location,
+		MinStock:          query.MinStock,
+		MaxStock:          query.MaxStock,
+		MinPrice:          query.MinPrice,
+		MaxPrice:          query.MaxPrice,
+		LowStock:          query.LowStock,
+		LowStockThreshold: query.LowStockThreshold,
+		Sort:              query.Sort,
+		Desc:              query.Order == "desc",
+	}
+	// A category also matches the products of its subcategories
+	if query.CategoryID > 0 {
+		tree, err := loadCategoryTree(ps.CategoryRepo.WithContext(ctx))
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.CategoryIDs = tree.descendants(query.CategoryID)
+	}
+	if filter.LowStock && query.LowStockThreshold == 0 {
+		filter.LowStockThreshold = DefaultLowStockThreshold
+	}
+	return ps.ProductRepo.WithContext(ctx).FindAll(filter, page, limit)
+}
+
+// checkSKU makes sure the SKU is not used by another product
+func (ps *productService) checkSKU(ctx context.Context, sku string, productID uint) (*string, error) {
+	if sku == "" {
+		return nil, nil
+	}
+	existing, err := ps.ProductRepo.WithContext(ctx).FindBySKU(sku)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != productID {
+		return nil, apperror.Conflict("sku_in_use", "sku already in use")
+	}
+	return &sku, nil
+}
+
+// checkSupplier makes sure the product's supplier, when it has one, exists
+func (ps *productService) checkSupplier(ctx context.Context, supplierID *uint) (*models.Supplier, error) {
+	if supplierID == nil {
+		return nil, nil
+	}
+	supplier, err := ps.SupplierRepo.WithContext(ctx).FindByID(*supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, apperror.Validation("invalid_supplier", "invalid supplier ID")
+	}
+	return supplier, nil
 }
 
 // Create adds a new product
-func (ps *productService) Create(req forms.ProductForm) (models.Product, error) {
-	category, err := ps.CategoryRepo.FindByID(req.CategoryID)
+func (ps *productService) Create(ctx context.Context, req forms.ProductForm) (models.Product, error) {
+	category, err := ps.CategoryRepo.WithContext(ctx).FindByID(req.CategoryID)
 	if err != nil || category == nil {
-		return models.Product{}, errors.New("invalid category ID")
+		return models.Product{}, apperror.Validation("invalid_category", "invalid category ID")
+	}
+
+	sku, err := ps.checkSKU(ctx, req.SKU, 0)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	supplier, err := ps.checkSupplier(ctx, req.SupplierID)
+	if err != nil {
+		return models.Product{}, err
 	}
 
 	product := models.Product{
 		Name:       req.Name,
+		SKU:        sku,
+		Brand:      req.Brand,
 		Stock:      req.Stock,
 		Price:      req.Price,
 		Location:   req.Location,
 		CategoryID: req.CategoryID,
 		Category:   *category,
+		SupplierID: req.SupplierID,
+		Supplier:   supplier,
 	}
 
-	if err := ps.ProductRepo.Create(&product); err != nil {
+	if err := ps.ProductRepo.WithContext(ctx).Create(&product); err != nil {
 		return models.Product{}, err
 	}
 
@@ -64,41 +137,61 @@ func (ps *productService) Create(req forms.ProductForm) (models.Product, error)
 }
 
 // GetByID retrieves a product by ID
-func (ps *productService) GetByID(id uint) (*models.Product, error) {
-	product, err := ps.ProductRepo.FindByID(id)
+func (ps *productService) GetByID(ctx context.Context, id uint) (*models.Product, error) {
+	product, err := ps.ProductRepo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, apperror.NotFound("product_not_found", "product not found")
 	}
 	return product, nil
 }
 
 // Update modifies an existing product
-func (ps *productService) Update(id string, req forms.UpdateProductForm) (models.Product, error) {
+func (ps *productService) Update(ctx context.Context, actorID uint, id string, req forms.UpdateProductForm) (models.Product, error) {
 	productID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		return models.Product{}, errors.New("invalid product ID")
+		return models.Product{}, apperror.Validation("invalid_product_id", "invalid product ID")
 	}
 
-	existingProduct, err := ps.ProductRepo.FindByID(uint(productID))
-    if err != nil || existingProduct == nil {
-        return models.Product{}, errors.New("product not found")
+	existingProduct, err := ps.ProductRepo.WithContext(ctx).FindByID(uint(productID))
+    if err != nil {
+        return models.Product{}, err
+    }
+    if existingProduct == nil {
+        return models.Product{}, apperror.NotFound("product_not_found", "product not found")
     }
 
-	category, err := ps.CategoryRepo.FindByID(req.CategoryID)
-	if err != nil || category == nil {
-		return models.Product{}, errors.New("invalid category ID")
+	category, err := ps.CategoryRepo.WithContext(ctx).FindByID(req.CategoryID)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if category == nil {
+		return models.Product{}, apperror.Validation("invalid_category", "invalid category ID")
+	}
+
+	sku, err := ps.checkSKU(ctx, req.SKU, uint(productID))
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	supplier, err := ps.checkSupplier(ctx, req.SupplierID)
+	if err != nil {
+		return models.Product{}, err
 	}
 
 	product := models.Product{
 		ID:         uint(productID),
 		Name:       req.Name,
+		SKU:        sku,
+		Brand:      req.Brand,
 		Stock:      existingProduct.Stock,
 		Price:      req.Price,
 		Location:   req.Location,
 		CategoryID: req.CategoryID,
+		SupplierID: req.SupplierID,
+		Supplier:   supplier,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -110,15 +203,17 @@ func (ps *productService) Update(id string, req forms.UpdateProductForm) (models
             OldPrice:    existingProduct.Price,
             NewPrice:    req.Price,
             DateChanged: time.Now(),
+            ChangedByID: &actorID,
+            Source:      models.PriceSourceManual,
             CreatedAt:   time.Now(),
             UpdatedAt:   time.Now(),
         }
-        if err := ps.PriceHistoryRepo.Create(&history); err != nil {
+        if err := ps.PriceHistoryRepo.WithContext(ctx).Create(&history); err != nil {
             return models.Product{}, err
         }
     }
 
-	if err := ps.ProductRepo.Update(&product); err != nil {
+	if err := ps.ProductRepo.WithContext(ctx).Update(&product); err != nil {
 		return models.Product{}, err
 	}
 
@@ -126,15 +221,15 @@ func (ps *productService) Update(id string, req forms.UpdateProductForm) (models
 }
 
 // Delete removes a product by ID
-func (ps *productService) Delete(id string) error {
+func (ps *productService) Delete(ctx context.Context, id string) error {
 	productID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		return errors.New("invalid product ID")
+		return apperror.Validation("invalid_product_id", "invalid product ID")
 	}
-	return ps.ProductRepo.Delete(uint(productID))
+	return ps.ProductRepo.WithContext(ctx).Delete(uint(productID))
 }
 
 // SalesReport returns paginated sales data per product
-func (ps *productService) GetSalesReport(page, limit int) ([]models.ProductSales, int64, error) {
-	return ps.ProductRepo.FindAllWithSales(page, limit)
+func (ps *productService) GetSalesReport(ctx context.Context, page, limit int) ([]models.ProductSales, int64, error) {
+	return ps.ProductRepo.WithContext(ctx).FindAllWithSales(page, limit)
 }
\ No newline at end of file
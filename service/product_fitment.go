@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+// FitmentImportError describes a rejected CSV line
+type FitmentImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// FitmentImportResult is returned by a CSV import. Nothing is saved when Errors is not empty.
+type FitmentImportResult struct {
+	Imported int                  `json:"imported"`
+	Errors   []FitmentImportError `json:"errors"`
+}
+
+type ProductFitmentService interface {
+	GetByProduct(ctx context.Context, productID uint) ([]models.ProductFitment, error)
+	Create(ctx context.Context, productID uint, form *forms.FitmentForm) (*models.ProductFitment, error)
+	Delete(ctx context.Context, id uint) error
+	Search(ctx context.Context, form *forms.FitmentSearchForm, page, limit int) ([]models.Product, int64, error)
+	ImportCSV(ctx context.Context, r io.Reader) (*FitmentImportResult, error)
+}
+
+type productFitmentService struct {
+	fitmentRepo repository.ProductFitmentRepository
+	productRepo repository.ProductRepository
+	vehicleRepo repository.VehicleRepository
+}
+
+func NewProductFitmentService(
+	fitmentRepo repository.ProductFitmentRepository,
+	productRepo repository.ProductRepository,
+	vehicleRepo repository.VehicleRepository,
+) ProductFitmentService {
+	return &productFitmentService{fitmentRepo, productRepo, vehicleRepo}
+}
+
+func (s *productFitmentService) GetByProduct(ctx context.Context, productID uint) ([]models.ProductFitment, error) {
+	product, err := s.productRepo.WithContext(ctx).FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, apperror.NotFound("product_not_found", "product not found")
+	}
+	return s.fitmentRepo.WithContext(ctx).FindByProductID(productID)
+}
+
+func (s *productFitmentService) Create(ctx context.Context, productID uint, form *forms.FitmentForm) (*models.ProductFitment, error) {
+	product, err := s.productRepo.WithContext(ctx).FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, apperror.NotFound("product_not_found", "product not found")
+	}
+
+	fitment := &models.ProductFitment{
+		ProductID: productID,
+		Make:      strings.TrimSpace(form.Make),
+		Model:     strings.TrimSpace(form.Model),
+		YearFrom:  form.YearFrom,
+		YearTo:    form.YearTo,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.fitmentRepo.WithContext(ctx).Create(fitment); err != nil {
+		return nil, err
+	}
+	return fitment, nil
+}
+
+func (s *productFitmentService) Delete(ctx context.Context, id uint) error {
+	return s.fitmentRepo.WithContext(ctx).Delete(id)
+}
+
+// Search returns the products fitting the given vehicle, or a vehicle from the registry
+func (s *productFitmentService) Search(ctx context.Context, form *forms.FitmentSearchForm, page, limit int) ([]models.Product, int64, error) {
+	vehicleMake, vehicleModel, year := form.Make, form.Model, form.Year
+
+	if form.VehicleID > 0 || form.Plate != "" {
+		var vehicle *models.Vehicle
+		var err error
+		if form.VehicleID > 0 {
+			vehicle, err = s.vehicleRepo.WithContext(ctx).FindByID(form.VehicleID)
+		} else {
+			vehicle, err = s.vehicleRepo.WithContext(ctx).FindByPlate(NormalizePlate(form.Plate))
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if vehicle == nil {
+			return nil, 0, apperror.NotFound("vehicle_not_found", "vehicle not found")
+		}
+		vehicleMake, vehicleModel, year = vehicle.Make, vehicle.Model, vehicle.Year
+	}
+
+	return s.fitmentRepo.WithContext(ctx).FindCompatibleProducts(vehicleMake, vehicleModel, year, page, limit)
+}
+
+// ImportCSV reads fitments from a CSV with the header
+// product_id,sku,make,model,year_from,year_to where either product_id or sku
+// identifies the product and year_to may be empty. The import is all-or-nothing.
+func (s *productFitmentService) ImportCSV(ctx context.Context, r io.Reader) (*FitmentImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, apperror.Validation("invalid_csv", "invalid CSV file")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"make", "model", "year_from"} {
+		if _, ok := columns[required]; !ok {
+			return nil, apperror.Validation("missing_csv_column", "missing CSV column "+required)
+		}
+	}
+	_, hasID := columns["product_id"]
+	_, hasSKU := columns["sku"]
+	if !hasID && !hasSKU {
+		return nil, apperror.Validation("missing_csv_column", "missing CSV column product_id or sku")
+	}
+
+	get := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	result := &FitmentImportResult{}
+	var fitments []*models.ProductFitment
+	skuCache := make(map[string]uint)
+	idCache := make(map[uint]bool)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, FitmentImportError{line, err.Error()})
+			continue
+		}
+
+		productID, msg := s.resolveProduct(ctx, get(record, "product_id"), get(record, "sku"), idCache, skuCache)
+		if msg != "" {
+			result.Errors = append(result.Errors, FitmentImportError{line, msg})
+			continue
+		}
+
+		fitment := &models.ProductFitment{
+			ProductID: productID,
+			Make:      get(record, "make"),
+			Model:     get(record, "model"),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if fitment.Make == "" || fitment.Model == "" {
+			result.Errors = append(result.Errors, FitmentImportError{line, "make and model are required"})
+			continue
+		}
+
+		fitment.YearFrom, err = strconv.Atoi(get(record, "year_from"))
+		if err != nil {
+			result.Errors = append(result.Errors, FitmentImportError{line, "invalid year_from"})
+			continue
+		}
+		if yearTo := get(record, "year_to"); yearTo != "" {
+			y, err := strconv.Atoi(yearTo)
+			if err != nil || y < fitment.YearFrom {
+				result.Errors = append(result.Errors, FitmentImportError{line, "invalid year_to"})
+				continue
+			}
+			fitment.YearTo = &y
+		}
+
+		fitments = append(fitments, fitment)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+	if err := s.fitmentRepo.WithContext(ctx).CreateMultiple(fitments); err != nil {
+		return nil, err
+	}
+	result.Imported = len(fitments)
+	return result, nil
+}
+
+func (s *productFitmentService) resolveProduct(ctx context.Context, id, sku string, idCache map[uint]bool, skuCache map[string]uint) (uint, string) {
+	if id != "" {
+		productID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return 0, "invalid product_id"
+		}
+		found, ok := idCache[uint(productID)]
+		if !ok {
+			product, err := s.productRepo.WithContext(ctx).FindByID(uint(productID))
+			if err != nil {
+				return 0, err.Error()
+			}
+			found = product != nil
+			idCache[uint(productID)] = found
+		}
+		if !found {
+			return 0, "product not found"
+		}
+		return uint(productID), ""
+	}
+
+	if sku == "" {
+		return 0, "product_id or sku is required"
+	}
+	productID, ok := skuCache[sku]
+	if !ok {
+		product, err := s.productRepo.WithContext(ctx).FindBySKU(sku)
+		if err != nil {
+			return 0, err.Error()
+		}
+		if product != nil {
+			productID = product.ID
+		}
+		skuCache[sku] = productID
+	}
+	if productID == 0 {
+		return 0, "product not found"
+	}
+	return productID, ""
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// DefaultBranch is used when a receipt is requested without a branch
+const DefaultBranch = "default"
+
+type ReceiptService interface {
+	Render(ctx context.Context, activityID uint, branch string, paperMM int, paid float64) (*utils.Receipt, error)
+	GetTemplate(ctx context.Context, branch string) (*models.ReceiptTemplate, error)
+	SaveTemplate(ctx context.Context, branch string, form *forms.ReceiptTemplateForm) (*models.ReceiptTemplate, error)
+}
+
+type receiptService struct {
+	activityRepo repository.ActivityRepository
+	templateRepo repository.ReceiptTemplateRepository
+	workshopName string
+}
+
+func NewReceiptService(
+	activityRepo repository.ActivityRepository,
+	templateRepo repository.ReceiptTemplateRepository,
+	cfg config.Workshop,
+) ReceiptService {
+	return &receiptService{activityRepo, templateRepo, cfg.Name}
+}
+
+// GetTemplate returns the branch template, falling back to the default branch
+// and finally to the configured workshop name.
+func (s *receiptService) GetTemplate(ctx context.Context, branch string) (*models.ReceiptTemplate, error) {
+	if branch == "" {
+		branch = DefaultBranch
+	}
+
+	template, err := s.templateRepo.WithContext(ctx).FindByBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil && branch != DefaultBranch {
+		template, err = s.templateRepo.WithContext(ctx).FindByBranch(DefaultBranch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if template == nil {
+		name := s.workshopName
+		if name == "" {
+			name = "Bengkel"
+		}
+		template = &models.ReceiptTemplate{
+			Branch:       branch,
+			WorkshopName: name,
+			Footer:       "Terima kasih atas kunjungan Anda",
+		}
+	}
+	return template, nil
+}
+
+func (s *receiptService) SaveTemplate(ctx context.Context, branch string, form *forms.ReceiptTemplateForm) (*models.ReceiptTemplate, error) {
+	template, err := s.templateRepo.WithContext(ctx).FindByBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		template = &models.ReceiptTemplate{
+			Branch:    branch,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	template.WorkshopName = form.WorkshopName
+	template.Address = form.Address
+	template.Phone = form.Phone
+	template.Footer = form.Footer
+	template.UpdatedAt = time.Now()
+
+	if err := s.templateRepo.WithContext(ctx).Save(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *receiptService) Render(ctx context.Context, activityID uint, branch string, paperMM int, paid float64) (*utils.Receipt, error) {
+	if paperMM != 58 && paperMM != 80 {
+		return nil, apperror.Validation("unsupported_paper_width", "unsupported paper width")
+	}
+
+	activity, err := s.activityRepo.WithContext(ctx).FindByID(activityID)
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		return nil, apperror.NotFound("activity_not_found", "activity not found")
+	}
+
+	if branch == "" {
+		branch = activity.Branch
+	}
+	template, err := s.GetTemplate(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := utils.NewReceipt(paperMM)
+
+	// Header
+	r.AddTitle(template.WorkshopName)
+	if template.Address != "" {
+		r.Add(template.Address, utils.AlignCenter, false)
+	}
+	if template.Phone != "" {
+		r.Add("Telp. "+template.Phone, utils.AlignCenter, false)
+	}
+	r.AddSeparator()
+
+	title := "STRUK PENJUALAN"
+	if activity.Type == "inbound" {
+		title = "BUKTI BARANG MASUK"
+	}
+	r.Add(title, utils.AlignCenter, true)
+	if activity.InvoiceNumber != nil {
+		r.AddPair("No", *activity.InvoiceNumber, false)
+	} else {
+		r.AddPair("No", fmt.Sprintf("#%d", activity.ID), false)
+	}
+	r.AddPair("Tanggal", activity.Date.Format("02/01/2006 15:04"), false)
+	r.AddPair("Kasir", activity.User.Name, false)
+	r.AddSeparator()
+
+	// Items
+	var subtotal, discount float64
+	for _, item := range activity.Items {
+		gross := item.PriceAtTime * float64(item.Quantity)
+		net := item.FinalPrice * float64(item.Quantity)
+
+		r.Add(item.Product.Name, utils.AlignLeft, false)
+		r.AddPair(fmt.Sprintf("  %d x %s", item.Quantity, utils.FormatRupiah(item.PriceAtTime)), utils.FormatRupiah(gross), false)
+		if gross > net {
+			r.AddPair("  Diskon", utils.FormatRupiah(net-gross), false)
+		}
+
+		subtotal += gross
+		discount += gross - net
+	}
+	r.AddSeparator()
+
+	// Totals
+	tax := (subtotal - discount) * activity.TaxRate / 100
+	total := subtotal - discount + tax
+	r.AddPair("Subtotal", utils.FormatRupiah(subtotal), false)
+	if discount > 0 {
+		r.AddPair("Diskon", utils.FormatRupiah(-discount), false)
+	}
+	if tax > 0 {
+		r.AddPair(fmt.Sprintf("PPN %g%%", activity.TaxRate), utils.FormatRupiah(tax), false)
+	}
+	r.AddPair("TOTAL", utils.FormatRupiah(total), true)
+
+	// Payments; the cash tendered only shows on paid activities
+	if paid > 0 {
+		if activity.PaymentStatus == "unpaid" {
+			return nil, apperror.Validation("activity_unpaid", "activity has not been paid")
+		}
+		if paid < total {
+			return nil, apperror.Validation("insufficient_payment", "paid amount is less than total")
+		}
+		r.AddPair("Tunai", utils.FormatRupiah(paid), false)
+		r.AddPair("Kembali", utils.FormatRupiah(paid-total), false)
+	}
+	r.AddPair("Status", paymentStatusLabel(activity.PaymentStatus), false)
+	r.AddSeparator()
+
+	// Footer
+	if template.Footer != "" {
+		r.Add(template.Footer, utils.AlignCenter, false)
+	}
+
+	return r, nil
+}
+
+// paymentStatusLabel is the payment status printed on receipts and invoices
+func paymentStatusLabel(status string) string {
+	if status == "unpaid" {
+		return "BELUM DIBAYAR"
+	}
+	return "LUNAS"
+}
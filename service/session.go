@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type SessionService interface {
+	Start(ctx context.Context, user *models.User, userAgent, ip string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, *models.User, error)
+	Logout(ctx context.Context, sessionID string) error
+	LogoutAll(ctx context.Context, userID uint) error
+	LogoutOthers(ctx context.Context, userID uint, keepSessionID string) error
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	MustResetPassword(ctx context.Context, userID uint) (bool, error)
+	ParseAccessToken(token string) (*utils.UserClaims, error)
+}
+
+type sessionService struct {
+	sessionRepo     repository.SessionRepository
+	userRepo        repository.UserRepository
+	jwt             *utils.JWT
+	refreshTokenTTL time.Duration
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository, cfg config.JWT) SessionService {
+	return &sessionService{sessionRepo, userRepo, utils.NewJWT(cfg.Secret.Value(), cfg.AccessTokenTTL), cfg.RefreshTokenTTL}
+}
+
+// Start opens a new session for a user who just authenticated
+func (s *sessionService) Start(ctx context.Context, user *models.User, userAgent, ip string) (*TokenPair, error) {
+	sessionID, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refresh, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	session := &models.UserSession{
+		ID:         sessionID,
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastUsedAt: time.Now(),
+		CreatedAt:  time.Now(),
+	}
+	if err := s.sessionRepo.WithContext(ctx).Create(session, token); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, sessionID, refresh)
+}
+
+// Refresh exchanges a refresh token for a new token pair. A refresh token that
+// was already used means it leaked, so the whole session is revoked.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, *models.User, error) {
+	stored, err := s.sessionRepo.WithContext(ctx).FindRefreshToken(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, nil, err
+	}
+	if stored == nil || stored.Session.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, nil, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	}
+	if stored.UsedAt != nil {
+		if err := s.sessionRepo.WithContext(ctx).Revoke(stored.SessionID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).FindByID(stored.Session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	}
+
+	refresh, next, err := s.newRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.sessionRepo.WithContext(ctx).Rotate(stored, next); err != nil {
+		if apperror.HasCode(err, "refresh_token_used") {
+			_ = s.sessionRepo.WithContext(ctx).Revoke(stored.SessionID)
+			return nil, nil, apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokens(user, stored.SessionID, refresh)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
+func (s *sessionService) Logout(ctx context.Context, sessionID string) error {
+	return s.sessionRepo.WithContext(ctx).Revoke(sessionID)
+}
+
+func (s *sessionService) LogoutAll(ctx context.Context, userID uint) error {
+	return s.sessionRepo.WithContext(ctx).RevokeAllForUser(userID)
+}
+
+func (s *sessionService) LogoutOthers(ctx context.Context, userID uint, keepSessionID string) error {
+	return s.sessionRepo.WithContext(ctx).RevokeOthersForUser(userID, keepSessionID)
+}
+
+// IsSessionActive is checked by the auth middleware on every request
+func (s *sessionService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	session, err := s.sessionRepo.WithContext(ctx).FindByID(sessionID)
+	if err != nil {
+		return false, err
+	}
+	return session != nil && session.RevokedAt == nil, nil
+}
+
+// MustResetPassword reports whether an admin reset the user's password and the
+// user has not picked a new one yet. It is checked by the auth middleware.
+func (s *sessionService) MustResetPassword(ctx context.Context, userID uint) (bool, error) {
+	user, err := s.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.MustResetPassword, nil
+}
+
+func (s *sessionService) newRefreshToken() (string, *models.RefreshToken, error) {
+	refresh, err := utils.RandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	return refresh, &models.RefreshToken{
+		TokenHash: utils.HashToken(refresh),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (s *sessionService) issueTokens(user *models.User, sessionID, refresh string) (*TokenPair, error) {
+	access, err := s.jwt.Generate(user.ID, user.Email, user.Role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.jwt.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// ParseAccessToken validates an access token and returns its claims
+func (s *sessionService) ParseAccessToken(token string) (*utils.UserClaims, error) {
+	return s.jwt.Validate(token)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+type SupplierService interface {
+	GetAll(ctx context.Context, page, limit int) ([]models.Supplier, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.Supplier, error)
+	Create(ctx context.Context, form *forms.SupplierForm) (*models.Supplier, error)
+}
+
+type supplierService struct {
+	supplierRepo repository.SupplierRepository
+}
+
+func NewSupplierService(supplierRepo repository.SupplierRepository) SupplierService {
+	return &supplierService{supplierRepo}
+}
+
+func (s *supplierService) GetAll(ctx context.Context, page, limit int) ([]models.Supplier, int64, error) {
+	return s.supplierRepo.WithContext(ctx).FindAll(page, limit)
+}
+
+func (s *supplierService) GetByID(ctx context.Context, id uint) (*models.Supplier, error) {
+	supplier, err := s.supplierRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, apperror.NotFound("supplier_not_found", "supplier not found")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) Create(ctx context.Context, form *forms.SupplierForm) (*models.Supplier, error) {
+	name := strings.TrimSpace(form.Name)
+
+	existing, err := s.supplierRepo.WithContext(ctx).FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperror.Conflict("supplier_exists", "supplier already registered")
+	}
+
+	supplier := &models.Supplier{
+		Name:      name,
+		Phone:     strings.TrimSpace(form.Phone),
+		Address:   strings.TrimSpace(form.Address),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.supplierRepo.WithContext(ctx).Create(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
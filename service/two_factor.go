@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+const (
+	loginChallengeTTL         = 5 * time.Minute
+	loginChallengeMaxAttempts = 5
+	recoveryCodeCount         = 10
+)
+
+// TwoFactorEnrollment is shown once so the user can add the secret to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qr_code"`
+}
+
+// LoginChallenge is returned by login instead of tokens when a second factor is needed
+type LoginChallenge struct {
+	Token              string `json:"challenge_token"`
+	ExpiresIn          int    `json:"expires_in"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+}
+
+// TwoFactorLogin is the outcome of a verified login challenge
+type TwoFactorLogin struct {
+	Tokens        *TokenPair
+	User          *models.User
+	RecoveryCodes []string // only set when the login completed a mandatory enrollment
+}
+
+type TwoFactorService interface {
+	IsRequired(ctx context.Context, user *models.User) (bool, error)
+	StartChallenge(ctx context.Context, user *models.User) (*LoginChallenge, error)
+	EnrollChallenge(ctx context.Context, challengeToken string) (*TwoFactorEnrollment, error)
+	VerifyChallenge(ctx context.Context, challengeToken, code, userAgent, ip string) (*TwoFactorLogin, error)
+	Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)
+	Reset(ctx context.Context, actorID, userID uint) error
+}
+
+type twoFactorService struct {
+	userRepo          repository.UserRepository
+	twoFactorRepo     repository.TwoFactorRepository
+	auditRepo         repository.UserAuditLogRepository
+	permissionService PermissionService
+	sessionService    SessionService
+	loginGuard        LoginGuardService
+	issuer            string
+}
+
+func NewTwoFactorService(
+	userRepo repository.UserRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	auditRepo repository.UserAuditLogRepository,
+	permissionService PermissionService,
+	sessionService SessionService,
+	loginGuard LoginGuardService,
+	cfg config.Login,
+) TwoFactorService {
+	return &twoFactorService{userRepo, twoFactorRepo, auditRepo, permissionService, sessionService, loginGuard, cfg.TOTPIssuer}
+}
+
+// IsRequired reports whether the user has to pass a second factor to log in,
+// either because they enrolled or because their role demands it
+func (s *twoFactorService) IsRequired(ctx context.Context, user *models.User) (bool, error) {
+	if user.TOTPEnabled {
+		return true, nil
+	}
+	return s.permissionService.RequiresTwoFactor(ctx, user.Role)
+}
+
+// StartChallenge issues a short-lived challenge after the password was verified
+func (s *twoFactorService) StartChallenge(ctx context.Context, user *models.User) (*LoginChallenge, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &models.LoginChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(loginChallengeTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := s.twoFactorRepo.WithContext(ctx).CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	return &LoginChallenge{
+		Token:              token,
+		ExpiresIn:          int(loginChallengeTTL.Seconds()),
+		EnrollmentRequired: !user.TOTPEnabled,
+	}, nil
+}
+
+// EnrollChallenge lets a user whose role requires 2FA set it up in the middle of logging in
+func (s *twoFactorService) EnrollChallenge(ctx context.Context, challengeToken string) (*TwoFactorEnrollment, error) {
+	_, user, err := s.openChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user)
+}
+
+// VerifyChallenge checks the TOTP or recovery code of a challenge and starts a
+// session. For users still enrolling, the first valid code turns 2FA on.
+func (s *twoFactorService) VerifyChallenge(ctx context.Context, challengeToken, code, userAgent, ip string) (*TwoFactorLogin, error) {
+	challenge, user, err := s.openChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginGuard.Check(ctx, user, user.Email, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	enrolling := !user.TOTPEnabled
+	if enrolling {
+		if user.TOTPSecret == "" {
+			return nil, apperror.Conflict("two_factor_not_started", "two-factor enrollment not started")
+		}
+		ok, err = s.checkTOTP(ctx, user, code)
+	} else {
+		ok, err = s.checkCode(ctx, user, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.twoFactorRepo.WithContext(ctx).AddChallengeAttempt(challenge.ID); err != nil {
+			return nil, err
+		}
+		if err := s.loginGuard.RecordFailure(ctx, user, user.Email, ip, userAgent, "wrong_2fa_code"); err != nil {
+			return nil, err
+		}
+		return nil, apperror.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+	}
+
+	if err := s.twoFactorRepo.WithContext(ctx).ConsumeChallenge(challenge.ID); err != nil {
+		return nil, apperror.Unauthorized("invalid_login_challenge", "invalid or expired login challenge")
+	}
+
+	result := &TwoFactorLogin{User: user}
+	if enrolling {
+		if result.RecoveryCodes, err = s.enable(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, user, ip, userAgent); err != nil {
+		return nil, err
+	}
+	if result.Tokens, err = s.sessionService.Start(ctx, user, userAgent, ip); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Enroll generates a new secret for a logged-in user. 2FA stays off until Confirm.
+func (s *twoFactorService) Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user)
+}
+
+// Confirm turns 2FA on once the user proves the authenticator works, and
+// returns the recovery codes
+func (s *twoFactorService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, apperror.Conflict("two_factor_enabled", "two-factor already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperror.Conflict("two_factor_not_started", "two-factor enrollment not started")
+	}
+
+	ok, err := s.checkTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+	}
+
+	return s.enable(ctx, user)
+}
+
+// Disable turns 2FA off, unless the user's role requires it
+func (s *twoFactorService) Disable(ctx context.Context, userID uint, password, code string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return apperror.Conflict("two_factor_disabled", "two-factor is not enabled")
+	}
+	if !utils.CheckHash(password, user.Password) {
+		return apperror.Unauthorized("incorrect_password", "password is incorrect")
+	}
+
+	required, err := s.permissionService.RequiresTwoFactor(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperror.Forbidden("two_factor_required", "two-factor is required for your role")
+	}
+
+	ok, err := s.checkCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperror.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+	}
+
+	return s.disable(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, apperror.Conflict("two_factor_disabled", "two-factor is not enabled")
+	}
+
+	ok, err := s.checkTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+	}
+
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+// Reset lets an admin remove 2FA from a user who lost both device and recovery
+// codes. Users whose role requires 2FA enroll again on their next login.
+func (s *twoFactorService) Reset(ctx context.Context, actorID, userID uint) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.disable(ctx, user); err != nil {
+		return err
+	}
+	if err := s.sessionService.LogoutAll(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.auditRepo.WithContext(ctx).Create(&models.UserAuditLog{
+		ActorID:      actorID,
+		TargetUserID: user.ID,
+		Action:       "reset_2fa",
+		CreatedAt:    time.Now(),
+	})
+}
+
+// openChallenge loads a challenge that can still be answered, with its user
+func (s *twoFactorService) openChallenge(ctx context.Context, challengeToken string) (*models.LoginChallenge, *models.User, error) {
+	challenge, err := s.twoFactorRepo.WithContext(ctx).FindChallenge(utils.HashToken(challengeToken))
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge == nil || challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) ||
+		challenge.Attempts >= loginChallengeMaxAttempts {
+		return nil, nil, apperror.Unauthorized("invalid_login_challenge", "invalid or expired login challenge")
+	}
+
+	user, err := s.userRepo.WithContext(ctx).FindByID(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, apperror.Unauthorized("invalid_login_challenge", "invalid or expired login challenge")
+	}
+	return challenge, user, nil
+}
+
+func (s *twoFactorService) getUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.NotFound("user_not_found", "user not found")
+	}
+	return user, nil
+}
+
+func (s *twoFactorService) enroll(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error) {
+	if user.TOTPEnabled {
+		return nil, apperror.Conflict("two_factor_enabled", "two-factor already enabled")
+	}
+
+	key, err := utils.NewTOTPKey(s.issuer, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = key.Secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{Secret: key.Secret, URI: key.URI, QRCode: key.QRCode}, nil
+}
+
+func (s *twoFactorService) enable(ctx context.Context, user *models.User) ([]string, error) {
+	user.TOTPEnabled = true
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+func (s *twoFactorService) disable(ctx context.Context, user *models.User) error {
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.WithContext(ctx).DeleteRecoveryCodes(user.ID)
+}
+
+// checkTOTP validates a TOTP code and remembers its time step against replays
+func (s *twoFactorService) checkTOTP(ctx context.Context, user *models.User, code string) (bool, error) {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	user.TOTPLastStep = step
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkCode accepts either a TOTP code or an unused recovery code
+func (s *twoFactorService) checkCode(ctx context.Context, user *models.User, code string) (bool, error) {
+	ok, err := s.checkTOTP(ctx, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+	return s.twoFactorRepo.WithContext(ctx).UseRecoveryCode(user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+}
+
+func (s *twoFactorService) newRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.RecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+
+	if err := s.twoFactorRepo.WithContext(ctx).ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
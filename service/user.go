@@ -1,9 +1,10 @@
 package service
 
 import (
-	"errors"
-
+	"context"
 	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
 	"github.com/sinscostank/bengkel-inventory/forms"
 	"github.com/sinscostank/bengkel-inventory/models"
 	"github.com/sinscostank/bengkel-inventory/repository"
@@ -11,57 +12,116 @@ import (
 )
 
 
+// dummyHash is compared against when the email is unknown, so such logins take
+// as long as a wrong password and their timing does not reveal which emails exist
+var dummyHash, _ = utils.GenerateHash("dummy password")
+
+// LoginResult carries either a token pair, or a challenge when a second factor is needed
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *LoginChallenge
+	User      *models.User
+}
 
 type UserService interface {
-	Login(req *forms.LoginForm) (string, *models.User, error)
-	Register(req *forms.RegisterForm) error
+	Login(ctx context.Context, req *forms.LoginForm, userAgent, ip string) (*LoginResult, error)
+	Register(ctx context.Context, req *forms.RegisterForm) (*models.User, error)
 }
 
 type userService struct {
-	UserRepo repository.UserRepository
+	UserRepo       repository.UserRepository
+	SessionService SessionService
+	LoginGuard     LoginGuardService
+	TwoFactor      TwoFactorService
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{UserRepo: repo}
+func NewUserService(repo repository.UserRepository, sessionService SessionService, loginGuard LoginGuardService, twoFactor TwoFactorService) UserService {
+	return &userService{UserRepo: repo, SessionService: sessionService, LoginGuard: loginGuard, TwoFactor: twoFactor}
 }
 
-func (us *userService) Register(req *forms.RegisterForm) error {
-	existingUser, _ := us.UserRepo.FindUserByEmail(req.Email)
+func (us *userService) Register(ctx context.Context, req *forms.RegisterForm) (*models.User, error) {
+	existingUser, _ := us.UserRepo.WithContext(ctx).FindUserByEmail(req.Email)
 	if existingUser != nil {
-		return errors.New("email already in use")
+		return nil, apperror.Conflict("email_in_use", "email already in use")
 	}
 
 	hashedPassword, err := utils.GenerateHash(req.Password)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	user := models.User{
 		Name:      req.Name,
 		Email:     req.Email,
 		Password:  hashedPassword,
-		Role:      "karyawan",
+		Role:      models.RoleKaryawan,
+		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	return us.UserRepo.CreateUser(&user)
+	if err := us.UserRepo.WithContext(ctx).CreateUser(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (us *userService) Login(req *forms.LoginForm) (string, *models.User, error) {
-	user, err := us.UserRepo.FindUserByEmail(req.Email)
+func (us *userService) Login(ctx context.Context, req *forms.LoginForm, userAgent, ip string) (*LoginResult, error) {
+	user, err := us.UserRepo.WithContext(ctx).FindUserByEmail(req.Email)
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+
+	if err := us.LoginGuard.Check(ctx, user, req.Email, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		utils.CheckHash(req.Password, dummyHash)
+		if err := us.LoginGuard.RecordFailure(ctx, nil, req.Email, ip, userAgent, "unknown_email"); err != nil {
+			return nil, err
+		}
+		return nil, apperror.Unauthorized("invalid_credentials", "invalid credentials")
+	}
+
+	if !utils.CheckHash(req.Password, user.Password) {
+		if err := us.LoginGuard.RecordFailure(ctx, user, req.Email, ip, userAgent, "wrong_password"); err != nil {
+			return nil, err
+		}
+		return nil, apperror.Unauthorized("invalid_credentials", "invalid credentials")
+	}
+
+	if !user.IsActive {
+		if err := us.LoginGuard.RecordFailure(ctx, user, req.Email, ip, userAgent, "deactivated"); err != nil {
+			return nil, err
+		}
+		return nil, apperror.Forbidden("account_deactivated", "account is deactivated")
+	}
+
+	// The failures are only cleared once the second factor is verified too
+	required, err := us.TwoFactor.IsRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		if err := us.LoginGuard.Release(ctx, req.Email, ip); err != nil {
+			return nil, err
+		}
+		challenge, err := us.TwoFactor.StartChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge, User: user}, nil
 	}
 
-	if user == nil || !utils.CheckHash(req.Password, user.Password) {
-		return "", nil, errors.New("invalid credentials")
+	if err := us.LoginGuard.RecordSuccess(ctx, user, ip, userAgent); err != nil {
+		return nil, err
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
+	tokens, err := us.SessionService.Start(ctx, user, userAgent, ip)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return token, user, nil
+	return &LoginResult{Tokens: tokens, User: user}, nil
 }
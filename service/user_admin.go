@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+	"github.com/sinscostank/bengkel-inventory/utils"
+)
+
+// UserAdminService holds the admin-only user management operations.
+// Every change is recorded in the user audit trail with the acting admin.
+type UserAdminService interface {
+	GetAll(ctx context.Context, page, limit int) ([]models.User, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	Create(ctx context.Context, actorID uint, form *forms.CreateUserForm) (*models.User, error)
+	Update(ctx context.Context, actorID, id uint, form *forms.UpdateUserForm) (*models.User, error)
+	ChangeRole(ctx context.Context, actorID, id uint, role string) (*models.User, error)
+	SetActive(ctx context.Context, actorID, id uint, active bool) (*models.User, error)
+	ForcePasswordReset(ctx context.Context, actorID, id uint) (string, error)
+	GetAuditTrail(ctx context.Context, id uint) ([]models.UserAuditLog, error)
+	Unlock(ctx context.Context, actorID, id uint) error
+	GetLoginHistory(ctx context.Context, id uint, page, limit int) ([]models.LoginAttempt, int64, error)
+}
+
+type userAdminService struct {
+	userRepo       repository.UserRepository
+	auditRepo      repository.UserAuditLogRepository
+	roleRepo       repository.RoleRepository
+	sessionService SessionService
+	loginGuard     LoginGuardService
+}
+
+func NewUserAdminService(
+	userRepo repository.UserRepository,
+	auditRepo repository.UserAuditLogRepository,
+	roleRepo repository.RoleRepository,
+	sessionService SessionService,
+	loginGuard LoginGuardService,
+) UserAdminService {
+	return &userAdminService{userRepo, auditRepo, roleRepo, sessionService, loginGuard}
+}
+
+func (s *userAdminService) GetAll(ctx context.Context, page, limit int) ([]models.User, int64, error) {
+	return s.userRepo.WithContext(ctx).FindAll(page, limit)
+}
+
+func (s *userAdminService) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.userRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.NotFound("user_not_found", "user not found")
+	}
+	return user, nil
+}
+
+func (s *userAdminService) Create(ctx context.Context, actorID uint, form *forms.CreateUserForm) (*models.User, error) {
+	existingUser, err := s.userRepo.WithContext(ctx).FindUserByEmail(form.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, apperror.Conflict("email_in_use", "email already in use")
+	}
+
+	if err := s.checkRole(ctx, form.Role); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.GenerateHash(form.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Name:      form.Name,
+		Email:     form.Email,
+		Password:  hashedPassword,
+		Role:      form.Role,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.userRepo.WithContext(ctx).CreateUser(user); err != nil {
+		return nil, err
+	}
+
+	return user, s.audit(ctx, actorID, user.ID, "create", fmt.Sprintf("role=%s", user.Role))
+}
+
+func (s *userAdminService) Update(ctx context.Context, actorID, id uint, form *forms.UpdateUserForm) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.Email != user.Email {
+		existingUser, err := s.userRepo.WithContext(ctx).FindUserByEmail(form.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existingUser != nil {
+			return nil, apperror.Conflict("email_in_use", "email already in use")
+		}
+	}
+
+	details := fmt.Sprintf("name: %s -> %s, email: %s -> %s", user.Name, form.Name, user.Email, form.Email)
+	user.Name = form.Name
+	user.Email = form.Email
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, s.audit(ctx, actorID, user.ID, "update", details)
+}
+
+func (s *userAdminService) ChangeRole(ctx context.Context, actorID, id uint, role string) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	if err := s.checkRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("role: %s -> %s", user.Role, role)
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).UpdateKeepingPermission(user, models.PermRoleManage); err != nil {
+		return nil, err
+	}
+
+	// Tokens carry the old role, so the user has to log in again
+	if err := s.sessionService.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return user, s.audit(ctx, actorID, user.ID, "change_role", details)
+}
+
+func (s *userAdminService) SetActive(ctx context.Context, actorID, id uint, active bool) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive == active {
+		return user, nil
+	}
+
+	user.IsActive = active
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).UpdateKeepingPermission(user, models.PermRoleManage); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.sessionService.LogoutAll(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	action := "deactivate"
+	if active {
+		action = "reactivate"
+	}
+	return user, s.audit(ctx, actorID, user.ID, action, "")
+}
+
+// ForcePasswordReset replaces the password with a temporary one that the user
+// must change after logging in. The temporary password is returned once.
+func (s *userAdminService) ForcePasswordReset(ctx context.Context, actorID, id uint) (string, error) {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	tempPassword, err := utils.RandomToken(9)
+	if err != nil {
+		return "", err
+	}
+	hashedPassword, err := utils.GenerateHash(tempPassword)
+	if err != nil {
+		return "", err
+	}
+
+	user.Password = hashedPassword
+	user.MustResetPassword = true
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.WithContext(ctx).Update(user); err != nil {
+		return "", err
+	}
+
+	if err := s.sessionService.LogoutAll(ctx, user.ID); err != nil {
+		return "", err
+	}
+
+	return tempPassword, s.audit(ctx, actorID, user.ID, "force_password_reset", "")
+}
+
+func (s *userAdminService) GetAuditTrail(ctx context.Context, id uint) ([]models.UserAuditLog, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.auditRepo.WithContext(ctx).FindByUserID(id)
+}
+
+// Unlock lifts a login lockout before it expires
+func (s *userAdminService) Unlock(ctx context.Context, actorID, id uint) error {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.loginGuard.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+	return s.audit(ctx, actorID, user.ID, "unlock", "")
+}
+
+// GetLoginHistory returns the login attempts of a user, newest first
+func (s *userAdminService) GetLoginHistory(ctx context.Context, id uint, page, limit int) ([]models.LoginAttempt, int64, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.loginGuard.GetHistory(ctx, id, page, limit)
+}
+
+// checkRole makes sure the role exists in the roles table
+func (s *userAdminService) checkRole(ctx context.Context, name string) error {
+	role, err := s.roleRepo.WithContext(ctx).FindByName(name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return apperror.NotFound("role_not_found", "role not found")
+	}
+	return nil
+}
+
+func (s *userAdminService) audit(ctx context.Context, actorID, targetID uint, action, details string) error {
+	return s.auditRepo.WithContext(ctx).Create(&models.UserAuditLog{
+		ActorID:      actorID,
+		TargetUserID: targetID,
+		Action:       action,
+		Details:      details,
+		CreatedAt:    time.Now(),
+	})
+}
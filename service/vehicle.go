@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sinscostank/bengkel-inventory/apperror"
+	"github.com/sinscostank/bengkel-inventory/forms"
+	"github.com/sinscostank/bengkel-inventory/models"
+	"github.com/sinscostank/bengkel-inventory/repository"
+)
+
+type VehicleService interface {
+	GetAll(ctx context.Context, page, limit int) ([]models.Vehicle, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.Vehicle, error)
+	Create(ctx context.Context, form *forms.VehicleForm) (*models.Vehicle, error)
+}
+
+type vehicleService struct {
+	vehicleRepo repository.VehicleRepository
+}
+
+func NewVehicleService(vehicleRepo repository.VehicleRepository) VehicleService {
+	return &vehicleService{vehicleRepo}
+}
+
+// NormalizePlate upper-cases a plate number and collapses its spaces ("b  1234 xyz" -> "B 1234 XYZ")
+func NormalizePlate(plate string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(plate)), " ")
+}
+
+func (s *vehicleService) GetAll(ctx context.Context, page, limit int) ([]models.Vehicle, int64, error) {
+	return s.vehicleRepo.WithContext(ctx).FindAll(page, limit)
+}
+
+func (s *vehicleService) GetByID(ctx context.Context, id uint) (*models.Vehicle, error) {
+	vehicle, err := s.vehicleRepo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, apperror.NotFound("vehicle_not_found", "vehicle not found")
+	}
+	return vehicle, nil
+}
+
+func (s *vehicleService) Create(ctx context.Context, form *forms.VehicleForm) (*models.Vehicle, error) {
+	plate := NormalizePlate(form.PlateNumber)
+
+	existing, err := s.vehicleRepo.WithContext(ctx).FindByPlate(plate)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperror.Conflict("plate_in_use", "plate number already registered")
+	}
+
+	vehicle := &models.Vehicle{
+		PlateNumber: plate,
+		OwnerName:   form.OwnerName,
+		Make:        strings.TrimSpace(form.Make),
+		Model:       strings.TrimSpace(form.Model),
+		Year:        form.Year,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.vehicleRepo.WithContext(ctx).Create(vehicle); err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
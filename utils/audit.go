@@ -0,0 +1,13 @@
+// utils/audit.go
+package utils
+
+// Context keys shared by handlers and the audit middleware
+const (
+	// AuditBeforeKey holds the state of an entity before the handler changed it
+	AuditBeforeKey = "auditBefore"
+	// AuditActorKey holds the ID of the user an unauthenticated request acted
+	// for, e.g. the new user on /register
+	AuditActorKey = "auditActor"
+	// RequestIDKey holds the ID of the current request
+	RequestIDKey = "requestID"
+)
@@ -2,49 +2,65 @@
 package utils
 
 import (
-	"os"
 	"errors"
 	"github.com/dgrijalva/jwt-go"
 	"time"
 )
 
-// Secret key to sign JWTs (should be moved to environment variables in production)
-var SecretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
 // UserClaims is the custom claims structure for the JWT.
 type UserClaims struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID        uint     `json:"id"`
+	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	SessionID string   `json:"sid"`
+	APIKeyID  uint     `json:"-"` // set instead of SessionID for API key requests
+	Scopes    []string `json:"-"` // permissions of the API key
 	jwt.StandardClaims
 }
 
-// GenerateJWT generates a JWT token for the user
-func GenerateJWT(userID uint, email, role string) (string, error) {
+// JWT signs and validates access tokens with the configured secret
+type JWT struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+func NewJWT(secret string, accessTTL time.Duration) *JWT {
+	return &JWT{secret: []byte(secret), accessTTL: accessTTL}
+}
+
+// AccessTokenTTL is how long an access token stays valid
+func (j *JWT) AccessTokenTTL() time.Duration {
+	return j.accessTTL
+}
+
+// Generate creates a short-lived access token for a user session
+func (j *JWT) Generate(userID uint, email, role, sessionID string) (string, error) {
 	claims := UserClaims{
-		ID:    userID,
-		Email: email,
-		Role:  role,
+		ID:        userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24).Unix(),
+			ExpiresAt: time.Now().Add(j.accessTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
 			Issuer:    "bengkel-inventory",
 		},
 	}
 
 	// Create the token with claims and sign it with the secret key
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(SecretKey)
+	return token.SignedString(j.secret)
 }
 
-// ValidateJWT validates a JWT token and returns the claims
-func ValidateJWT(tokenString string) (*UserClaims, error) {
+// Validate checks a JWT token and returns the claims
+func (j *JWT) Validate(tokenString string) (*UserClaims, error) {
 	// Parse the JWT string and validate it
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Ensure that the signing method is correct
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return SecretKey, nil
+		return j.secret, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,75 @@
+// utils/mailer.go
+package utils
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/sinscostank/bengkel-inventory/config"
+)
+
+// Mailer sends plain-text emails
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns an SMTP mailer when an SMTP host is configured, otherwise
+// a mailer that only logs the message (useful in development).
+func NewMailer(cfg config.SMTP) Mailer {
+	if cfg.Host == "" {
+		return LogMailer{}
+	}
+
+	return &SMTPMailer{
+		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Host:     cfg.Host,
+		Username: cfg.User,
+		Password: cfg.Password.Value(),
+		From:     cfg.From,
+	}
+}
+
+// SMTPMailer delivers mail through an SMTP server. Authentication is skipped
+// when Username is empty, which lets it talk to a local SMTP stand-in.
+type SMTPMailer struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// LogMailer logs that an email was not sent instead of sending it. The body is
+// never logged since it may hold a live password reset token; use a local SMTP
+// stand-in such as MailHog to read mails in development.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	slog.Info("mail not sent, SMTP is not configured", "to", to, "subject", subject)
+	return nil
+}
@@ -0,0 +1,58 @@
+package utils_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sinscostank/bengkel-inventory/config"
+	"github.com/sinscostank/bengkel-inventory/utils"
+	"github.com/sinscostank/bengkel-inventory/utils/smtptest"
+)
+
+func TestSMTPMailerDelivers(t *testing.T) {
+	server := smtptest.NewServer(t)
+	mailer := utils.NewMailer(config.SMTP{Host: server.Host, Port: server.Port, From: "no-reply@bengkel.local"})
+
+	if err := mailer.Send("budi@example.com", "Reset password", "Halo Budi,\n\nkode: abc123\n"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msg := server.Next(t)
+	if msg.From != "no-reply@bengkel.local" || len(msg.To) != 1 || msg.To[0] != "budi@example.com" {
+		t.Errorf("envelope = %s -> %v", msg.From, msg.To)
+	}
+	for _, want := range []string{"Subject: Reset password", "Content-Type: text/plain; charset=UTF-8", "kode: abc123"} {
+		if !strings.Contains(msg.Data, want) {
+			t.Errorf("mail lacks %q:\n%s", want, msg.Data)
+		}
+	}
+}
+
+func TestSMTPMailerReportsFailures(t *testing.T) {
+	// Nothing listens on port 1
+	mailer := utils.NewMailer(config.SMTP{Host: "127.0.0.1", Port: "1", From: "no-reply@bengkel.local"})
+
+	if err := mailer.Send("budi@example.com", "Reset password", "body"); err == nil {
+		t.Fatal("Send succeeded without a server")
+	}
+}
+
+func TestLogMailerDoesNotLogTheBody(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	if err := utils.NewMailer(config.SMTP{}).Send("budi@example.com", "Reset password", "kode: secret-reset-token"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if strings.Contains(logs.String(), "secret-reset-token") {
+		t.Errorf("the body was logged:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "budi@example.com") {
+		t.Errorf("the recipient was not logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,188 @@
+// utils/receipt.go
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Paper widths supported by the thermal printers, in characters per line (font A)
+const (
+	Paper58mm = 32
+	Paper80mm = 48
+)
+
+// Text alignment for a receipt line
+const (
+	AlignLeft = iota
+	AlignCenter
+	AlignRight
+)
+
+// ESC/POS command bytes
+var (
+	escInit      = []byte{0x1B, 0x40}
+	escAlign     = []byte{0x1B, 0x61}
+	escBold      = []byte{0x1B, 0x45}
+	gsCharSize   = []byte{0x1D, 0x21}
+	gsPartialCut = []byte{0x1D, 0x56, 0x42, 0x00}
+)
+
+// ReceiptLine is a single printed line
+type ReceiptLine struct {
+	Text   string
+	Align  int
+	Bold   bool
+	Double bool
+}
+
+// Receipt is a printer-independent receipt layout
+type Receipt struct {
+	Width int
+	Lines []ReceiptLine
+}
+
+// NewReceipt creates an empty receipt for the given paper width in mm (58 or 80)
+func NewReceipt(paperMM int) *Receipt {
+	width := Paper58mm
+	if paperMM == 80 {
+		width = Paper80mm
+	}
+	return &Receipt{Width: width}
+}
+
+// Add appends a line, wrapping it to the paper width
+func (r *Receipt) Add(text string, align int, bold bool) {
+	for _, l := range wrap(text, r.Width) {
+		r.Lines = append(r.Lines, ReceiptLine{Text: l, Align: align, Bold: bold})
+	}
+}
+
+// AddTitle appends a double-size centered line
+func (r *Receipt) AddTitle(text string) {
+	// Double width halves the characters per line
+	for _, l := range wrap(text, r.Width/2) {
+		r.Lines = append(r.Lines, ReceiptLine{Text: l, Align: AlignCenter, Bold: true, Double: true})
+	}
+}
+
+// AddPair appends a line with a left label and a right-aligned value
+func (r *Receipt) AddPair(label, value string, bold bool) {
+	gap := r.Width - len([]rune(label)) - len([]rune(value))
+	if gap < 1 {
+		r.Add(label, AlignLeft, bold)
+		r.Add(value, AlignRight, bold)
+		return
+	}
+	r.Lines = append(r.Lines, ReceiptLine{Text: label + strings.Repeat(" ", gap) + value, Bold: bold})
+}
+
+// AddSeparator appends a dashed line across the paper
+func (r *Receipt) AddSeparator() {
+	r.Lines = append(r.Lines, ReceiptLine{Text: strings.Repeat("-", r.Width)})
+}
+
+// Text renders the receipt as plain text for previews
+func (r *Receipt) Text() string {
+	var sb strings.Builder
+	for _, l := range r.Lines {
+		pad := r.Width - len([]rune(l.Text))
+		switch {
+		case pad <= 0 || l.Align == AlignLeft:
+			sb.WriteString(l.Text)
+		case l.Align == AlignCenter:
+			sb.WriteString(strings.Repeat(" ", pad/2) + l.Text)
+		case l.Align == AlignRight:
+			sb.WriteString(strings.Repeat(" ", pad) + l.Text)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ESCPOS renders the receipt as an ESC/POS byte stream ending with a paper cut
+func (r *Receipt) ESCPOS() []byte {
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	for _, l := range r.Lines {
+		buf.Write(escAlign)
+		buf.WriteByte(byte(l.Align))
+		buf.Write(escBold)
+		buf.WriteByte(boolByte(l.Bold))
+		buf.Write(gsCharSize)
+		if l.Double {
+			buf.WriteByte(0x11)
+		} else {
+			buf.WriteByte(0x00)
+		}
+		buf.WriteString(asciiOnly(l.Text))
+		buf.WriteByte('\n')
+	}
+	// Feed a few lines so the last line clears the cutter
+	buf.WriteString("\n\n\n")
+	buf.Write(gsPartialCut)
+	return buf.Bytes()
+}
+
+// FormatRupiah formats an amount as "Rp 15.000"
+func FormatRupiah(amount float64) string {
+	negative := amount < 0
+	s := fmt.Sprintf("%d", int64(math.Round(math.Abs(amount))))
+
+	var sb strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(c)
+	}
+
+	if negative {
+		return "-Rp " + sb.String()
+	}
+	return "Rp " + sb.String()
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(paragraph)
+		if len(runes) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		for len(runes) > width {
+			cut := width
+			// Prefer breaking on the last space inside the line
+			for i := width; i > 0; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+			runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// asciiOnly replaces characters outside the printer's default code page
+func asciiOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+// Package smtptest runs a minimal SMTP server for tests that send mail.
+package smtptest
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Message is a mail the server received
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Server accepts every mail sent to it, without authentication or TLS
+type Server struct {
+	Host     string
+	Port     string
+	listener net.Listener
+	messages chan Message
+}
+
+// NewServer starts a server on a free local port. It is stopped when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("starting SMTP server: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	s := &Server{Host: host, Port: port, listener: listener, messages: make(chan Message, 16)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// Next waits for the next mail, failing the test when none arrives in time
+func (s *Server) Next(t testing.TB) Message {
+	t.Helper()
+	select {
+	case msg := <-s.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+		return Message{}
+	}
+}
+
+// Count returns how many received mails have not been read with Next yet
+func (s *Server) Count() int {
+	return len(s.messages)
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	reply := func(line string) { tp.PrintfLine("%s", line) }
+
+	reply("220 smtptest ready")
+	var msg Message
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 smtptest")
+		case "MAIL":
+			msg = Message{From: address(line)}
+			reply("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, address(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.messages <- msg
+			reply("250 OK")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// address takes the address out of "MAIL FROM:<a@b>" or "RCPT TO:<a@b>"
+func address(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+